@@ -0,0 +1,70 @@
+package doctor
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
+	wp "github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/workspace"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/doctor"
+)
+
+// Skip turns off the automatic preflight of prepare and integrate, set it with --skip-doctor
+var Skip bool
+
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check everything the release commands need",
+	Long: `Use this command to check the GitHub authentication and token scopes, the push permission and labels on each repo,
+the tools the release commands run and the disk space for the clones. Each problem comes with a fix.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		results := doctor.Run(doctor.All(workspaceRoot()))
+		printResults(results, true)
+		if doctor.HasFailures(results) {
+			utils.ExitIfError(errors.New("some checks failed, see the fixes above"), 1)
+		}
+	},
+}
+
+// Preflight runs the checks before a command starts and returns an error if any of them failed.
+// Only the problems are printed.
+func Preflight(checks func(dir string) []doctor.Check) error {
+	if Skip {
+		return nil
+	}
+	console.Info("Running the preflight checks, skip them with --skip-doctor")
+	results := doctor.Run(checks(workspaceRoot()))
+	printResults(results, false)
+	if doctor.HasFailures(results) {
+		return errors.New("the preflight checks failed, see the fixes above or run `gbm-cli doctor`")
+	}
+	return nil
+}
+
+// The clones go in the workspaces, or the current directory if it can't be found
+func workspaceRoot() string {
+	root, err := wp.Root()
+	if err != nil {
+		return "."
+	}
+	return root
+}
+
+func printResults(results []doctor.Result, all bool) {
+	for _, r := range results {
+		switch r.Status {
+		case doctor.Ok:
+			if all {
+				console.Print(console.Row, "✓ %s: %s", r.Name, r.Detail)
+			}
+		case doctor.Warning:
+			console.Warn("%s: %s", r.Name, r.Detail)
+		case doctor.Failed:
+			console.Error(errors.New(r.Name + ": " + r.Detail))
+		}
+		if r.Status != doctor.Ok && r.Fix != "" {
+			console.Print(console.Highlight, "  → %s", r.Fix)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package lifecycle runs the cleanup hooks of a command however it ends:
+// returning, exiting on an error, a signal or a panic.
+package lifecycle
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+// Phase orders the hooks, lower phases run first
+type Phase int
+
+const (
+	// StopCommands stops the running shell commands and their children
+	StopCommands Phase = iota
+	// RemoveDirs removes the workspace directories
+	RemoveDirs
+	// ReleaseLocks releases the locks on the release
+	ReleaseLocks
+	// SaveState writes what's needed to resume or report on the run
+	SaveState
+)
+
+type hook struct {
+	name  string
+	phase Phase
+	seq   int
+	once  *sync.Once
+	fn    func()
+}
+
+var (
+	mu    sync.Mutex
+	hooks []hook
+	seq   int
+)
+
+func init() {
+	OnExit("stop commands", StopCommands, stopCommands)
+}
+
+// Waits for the killed commands so the directories aren't removed while they write to them
+func stopCommands() {
+	if !shell.Stop(shell.StopTimeout) {
+		console.Warn("Some commands were still running after %s", shell.StopTimeout)
+	}
+}
+
+// OnExit registers a hook run when the command ends. Hooks of the same phase run in
+// the order they were registered. The returned function runs the hook early, e.g. when
+// cleaning up at the end of a successful run, and unregisters it.
+func OnExit(name string, phase Phase, fn func()) func() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	seq++
+	h := hook{name: name, phase: phase, seq: seq, once: &sync.Once{}, fn: fn}
+	hooks = append(hooks, h)
+
+	id := h.seq
+	return func() {
+		h.once.Do(h.fn)
+		mu.Lock()
+		defer mu.Unlock()
+		for i, o := range hooks {
+			if o.seq == id {
+				hooks = append(hooks[:i], hooks[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// RunHooks runs the registered hooks by phase. Each hook runs at most once,
+// and a panicking hook doesn't stop the following ones.
+func RunHooks() {
+	mu.Lock()
+	pending := append([]hook{}, hooks...)
+	hooks = nil
+	mu.Unlock()
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].phase != pending[j].phase {
+			return pending[i].phase < pending[j].phase
+		}
+		return pending[i].seq < pending[j].seq
+	})
+
+	for _, h := range pending {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					console.Warn("The %s cleanup failed: %v", h.name, r)
+				}
+			}()
+			h.once.Do(h.fn)
+		}()
+	}
+}
+
+// Exit runs the hooks and exits with the code
+func Exit(code int) {
+	RunHooks()
+	os.Exit(code)
+}
+
+// HandleSignals runs the hooks and exits when the process is interrupted, terminated or its terminal closes.
+// The exit code follows the shell convention of 128 + the signal number.
+func HandleSignals() {
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		sig := <-sigchan
+		console.Warn("Received %s, cleaning up", sig)
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		Exit(code)
+	}()
+}
+
+// Recover runs the hooks when the command panics. Defer it at the top of the command.
+func Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	console.Error(fmt.Errorf("unexpected error: %v\n%s", r, debug.Stack()))
+	Exit(2)
+}
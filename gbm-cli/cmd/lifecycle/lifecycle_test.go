@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunHooks(t *testing.T) {
+	reset := func() {
+		mu.Lock()
+		hooks = nil
+		mu.Unlock()
+	}
+
+	t.Run("It runs the hooks by phase and registration order", func(t *testing.T) {
+		reset()
+		ran := []string{}
+		OnExit("lock", ReleaseLocks, func() { ran = append(ran, "lock") })
+		OnExit("dir a", RemoveDirs, func() { ran = append(ran, "dir a") })
+		OnExit("state", SaveState, func() { ran = append(ran, "state") })
+		OnExit("dir b", RemoveDirs, func() { ran = append(ran, "dir b") })
+		OnExit("stop", StopCommands, func() { ran = append(ran, "stop") })
+
+		RunHooks()
+
+		want := []string{"stop", "dir a", "dir b", "lock", "state"}
+		if !reflect.DeepEqual(ran, want) {
+			t.Fatalf("got %v want %v", ran, want)
+		}
+	})
+
+	t.Run("It runs a hook only once when run early", func(t *testing.T) {
+		reset()
+		count := 0
+		run := OnExit("dir", RemoveDirs, func() { count++ })
+
+		run()
+		run()
+		RunHooks()
+
+		if count != 1 {
+			t.Fatalf("Expected the hook to run once, ran %d times", count)
+		}
+	})
+
+	t.Run("It keeps running the hooks after one panics", func(t *testing.T) {
+		reset()
+		ran := false
+		OnExit("broken", RemoveDirs, func() { panic("oops") })
+		OnExit("lock", ReleaseLocks, func() { ran = true })
+
+		RunHooks()
+
+		if !ran {
+			t.Fatal("Expected the hook after the panic to run")
+		}
+	})
+}
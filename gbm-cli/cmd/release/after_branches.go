@@ -0,0 +1,107 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+var mergeBase, retarget, prune bool
+var afterBase string
+
+var AfterBranchesCmd = &cobra.Command{
+	Use:   "after-branches",
+	Short: "manage the gutenberg/after_<version> branches of the host apps",
+	Long: `Use this command to list the after branches created by integrate in WordPress-iOS and WordPress-Android.
+
+With the flags set it can also:
+  - merge the base branch into the release's after branches
+  - retarget the PRs based on the release's after branches once the integration PR is merged
+  - delete the after branches of past releases`,
+	Run: func(cmd *cobra.Command, args []string) {
+		semver, err := utils.GetVersionArg(args)
+		exitIfError(err, 1)
+		version := semver.String()
+
+		for _, rpo := range []string{repo.WordPressAndroidRepo, repo.WordPressIosRepo} {
+			branches, err := release.FindAfterBranches(rpo, afterBase)
+			if err != nil {
+				console.Warn(err.Error())
+				continue
+			}
+
+			console.Print(console.Heading, "\n%s/%s", repo.GetOrg(rpo), rpo)
+			console.Print(console.HeadingRow, "%-36s %-8s %-8s", "Branch", "Ahead", "Behind")
+			var current *release.AfterBranch
+			for i, b := range branches {
+				console.Print(console.Row, "• %-34s %-8d %-8d", b.Name, b.AheadBy, b.BehindBy)
+				if b.Version.Compare(semver) == 0 {
+					current = &branches[i]
+				}
+			}
+
+			if mergeBase || retarget {
+				if current == nil {
+					console.Warn("No %s branch on %s", release.AfterBranchName(version), rpo)
+				} else {
+					afterBranchActions(*current, version)
+				}
+			}
+
+			if prune {
+				for _, b := range release.StaleAfterBranches(branches, semver) {
+					if !console.Confirm("delete_after_branch", fmt.Sprintf("Delete %s on %s?", b.Name, rpo)) {
+						continue
+					}
+					if err := release.DeleteAfterBranch(b); err != nil {
+						console.Warn("Unable to delete %s: %v", b.Name, err)
+						continue
+					}
+					console.Info("Deleted %s", b.Name)
+				}
+			}
+		}
+	},
+}
+
+func afterBranchActions(ab release.AfterBranch, version string) {
+	if mergeBase {
+		if ab.BehindBy == 0 {
+			console.Info("%s is up to date with %s", ab.Name, ab.Base)
+		} else if merged, err := release.MergeBaseIntoAfterBranch(ab); err != nil {
+			console.Warn("Unable to merge %s into %s: %v", ab.Base, ab.Name, err)
+		} else if merged {
+			console.Info("Merged %s into %s", ab.Base, ab.Name)
+		}
+	}
+
+	if retarget {
+		merged, err := release.IntegrationPrMerged(ab.Repo, version)
+		if err != nil {
+			console.Warn("Unable to check the integration PR on %s: %v", ab.Repo, err)
+			return
+		}
+		if !merged {
+			console.Warn("The integration PR on %s is not merged yet, not retargeting PRs based on %s", ab.Repo, ab.Name)
+			return
+		}
+		prs, err := release.RetargetAfterBranchPrs(ab)
+		for _, pr := range prs {
+			console.Info("Retargeted %s to %s", pr.Url, ab.Base)
+		}
+		if err != nil {
+			console.Warn(err.Error())
+		}
+	}
+}
+
+func init() {
+	AfterBranchesCmd.Flags().BoolVar(&mergeBase, "merge", false, "Merge the base branch into the release's after branches")
+	AfterBranchesCmd.Flags().BoolVar(&retarget, "retarget", false, "Retarget PRs based on the release's after branches once the integration PR is merged")
+	AfterBranchesCmd.Flags().BoolVar(&prune, "prune", false, "Delete the after branches of releases prior to the version, unless PRs are based on them")
+	AfterBranchesCmd.Flags().StringVar(&afterBase, "base", "trunk", "Branch the after branches are compared with, merged from and retargeted to")
+}
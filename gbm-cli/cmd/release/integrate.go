@@ -7,16 +7,20 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	dr "github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/doctor"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
 	wp "github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/workspace"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/doctor"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release/integrate"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
 )
 
-var android, ios, both bool
-var hostVersion string
+var android, ios, both, skipDeps, reportComment, update bool
+var hostVersion, iosHostVersion, androidHostVersion, depsWrapper string
+var iosDepsSteps, androidDepsSteps []string
 
 var IntegrateCmd = &cobra.Command{
 	Use:   "integrate",
@@ -27,24 +31,70 @@ var IntegrateCmd = &cobra.Command{
 		exitIfError(err, 1)
 		version := semver.String()
 
+		// Integrate GBM into Android and iOS if both flags are set or neither flag is set
+		both = !android && !ios || ios && android
+
+		rpos := []string{}
+		if both || android {
+			rpos = append(rpos, repo.WordPressAndroidRepo)
+		}
+		if both || ios {
+			rpos = append(rpos, repo.WordPressIosRepo)
+		}
+		exitIfError(dr.Preflight(func(dir string) []doctor.Check {
+			return doctor.Integrate(dir, skipDeps, rpos...)
+		}), 1)
+
+		exitIfError(workspace.Open("integrate", version), 1)
+		utils.StartReport(cmd, "integrate", version)
+		defer workspace.Cleanup()
+		if keepTempDir {
+			workspace.Keep()
+		}
+		tempDir = workspace.Dir()
+
 		gbmPr, err := release.FindGbmReleasePr(version)
 		exitIfError(err, 1)
 		if gbmPr.Number == 0 {
 			exitIfError(errors.New("no GBM PR found"), 1)
 		}
 
+		iosSteps, err := integrate.ParseSteps(iosDepsSteps)
+		exitIfError(err, 1)
+		androidSteps, err := integrate.ParseSteps(androidDepsSteps)
+		exitIfError(err, 1)
+
 		ri := integrate.ReleaseIntegration{
 			Version:    version,
 			BaseBranch: "trunk",
 			HeadBranch: fmt.Sprintf("gutenberg/integrate_release_%s", version),
 			GbmPr:      gbmPr,
+			Update:     update,
+			Deps: integrate.Deps{
+				Skip:    skipDeps,
+				Wrapper: depsWrapper,
+			},
 		}
 
-		if semver.IsPatchRelease() {
-			if hostVersion == "" {
-				exitIfError(errors.New("host version is required for patch releases"), 1)
+		// Patch releases are integrated into the current release branch of each host app.
+		// The iOS and Android versions can differ so they are resolved separately.
+		hostBaseBranch := func(rpo, platformVersion string) string {
+			hv := platformVersion
+			if hv == "" {
+				hv = hostVersion
 			}
-			ri.BaseBranch = fmt.Sprintf("release/%s", hostVersion)
+			if hv == "" {
+				latest, err := release.FindLatestHostVersion(rpo)
+				exitIfError(err, 1)
+
+				prompt := fmt.Sprintf("Integrate into the %s branch on %s?", release.HostReleaseBranch(latest), rpo)
+				if !console.Confirm("use_latest_host_version", prompt) {
+					exitIfError(fmt.Errorf("host version is required for patch releases, set it with --host-version or the platform flags"), 1)
+				}
+				hv = latest
+			}
+			exitIfError(release.ValidateHostVersion(rpo, hv), 1)
+			return release.HostReleaseBranch(hv)
 		}
 
 		results := []gh.PullRequest{}
@@ -54,8 +104,12 @@ var IntegrateCmd = &cobra.Command{
 			err := os.MkdirAll(androidDir, os.ModePerm)
 			exitIfError(err, 1)
 			androidRi := ri
+			if semver.IsPatchRelease() {
+				androidRi.BaseBranch = hostBaseBranch(repo.WordPressAndroidRepo, androidHostVersion)
+			}
 			target := integrate.AndroidIntegration{}
 			androidRi.Target = target
+			androidRi.Deps.Steps = androidSteps
 			pr, err := androidRi.Run(filepath.Join(tempDir, "android"))
 			if err != nil {
 				console.Warn(err.Error())
@@ -69,8 +123,12 @@ var IntegrateCmd = &cobra.Command{
 			exitIfError(err, 1)
 
 			iosRi := ri
+			if semver.IsPatchRelease() {
+				iosRi.BaseBranch = hostBaseBranch(repo.WordPressIosRepo, iosHostVersion)
+			}
 			target := integrate.IosIntegration{}
 			iosRi.Target = target
+			iosRi.Deps.Steps = iosSteps
 			pr, err := iosRi.Run(filepath.Join(tempDir, "ios"))
 			if err != nil {
 				console.Warn(err.Error())
@@ -78,9 +136,6 @@ var IntegrateCmd = &cobra.Command{
 			results = append(results, pr)
 		}
 
-		// Integrate GBM into Android and iOS if both flags are set or neither flag is set
-		both = !android && !ios || ios && android
-
 		switch {
 		case both:
 			console.Info("Integrating GBM version %s into both iOS and Android", version)
@@ -104,6 +159,7 @@ var IntegrateCmd = &cobra.Command{
 				console.Info("Created PR %s", pr.Url)
 			}
 		}
+		utils.FinishReport(reportComment)
 	},
 }
 
@@ -118,8 +174,16 @@ func init() {
 			utils.Exit(code, workspace.Cleanup)
 		}
 	}
-	tempDir = workspace.Dir()
 	IntegrateCmd.Flags().BoolVarP(&android, "android", "a", false, "Only integrate Android")
 	IntegrateCmd.Flags().BoolVarP(&ios, "ios", "i", false, "Only integrate iOS")
-	IntegrateCmd.Flags().StringVarP(&hostVersion, "host-version", "V", "", "host app version")
+	IntegrateCmd.Flags().StringVarP(&hostVersion, "host-version", "V", "", "host app version for both platforms. Defaults to the latest release branch for patch releases")
+	IntegrateCmd.Flags().StringVar(&iosHostVersion, "ios-host-version", "", "WordPress-iOS version, overrides --host-version")
+	IntegrateCmd.Flags().StringVar(&androidHostVersion, "android-host-version", "", "WordPress-Android version, overrides --host-version")
+	IntegrateCmd.Flags().BoolVar(&skipDeps, "skip-deps", false, "Skip the dependency steps (e.g. `bundle install` and `rake dependencies` on iOS) and leave them to CI")
+	IntegrateCmd.Flags().StringVar(&repo.ForkOwner, "fork", repo.ForkOwner, "GitHub user to push the integration branches to. The PRs are opened from the user's forks")
+	IntegrateCmd.Flags().BoolVar(&update, "update", false, "Update the Gutenberg config of existing PRs and mark them ready for review once they point to the published release")
+	IntegrateCmd.Flags().BoolVar(&reportComment, "report-comment", false, "Post the run report as a collapsed comment on the created PRs")
+	IntegrateCmd.Flags().StringVar(&depsWrapper, "deps-wrapper", "", "Command to run the dependency steps through, e.g. 'docker run --rm -v {dir}:/src -w /src image'")
+	IntegrateCmd.Flags().StringArrayVar(&iosDepsSteps, "ios-deps-step", nil, "Dependency step to run on WordPress-iOS instead of the defaults, repeat for several steps")
+	IntegrateCmd.Flags().StringArrayVar(&androidDepsSteps, "android-deps-step", nil, "Dependency step to run on WordPress-Android instead of the defaults (none), repeat for several steps")
 }
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
@@ -17,7 +18,7 @@ var allCmd = &cobra.Command{
 	Run: func(cc *cobra.Command, args []string) {
 		var err error
 
-		preflight(args)
+		preflight(cc, "prepare-all", args)
 		defer workspace.Cleanup()
 
 		// Set up separate directories for each repo
@@ -41,6 +42,7 @@ var allCmd = &cobra.Command{
 			},
 			Repo: "gutenberg",
 		}
+		useLocalCheckout(&build)
 
 		isPatch := version.IsPatchRelease()
 
@@ -64,6 +66,7 @@ var allCmd = &cobra.Command{
 			},
 			Repo: "gutenberg-mobile",
 		}
+		useLocalCheckout(&build)
 
 		if isPatch {
 			tagName := version.PriorVersion().Vstring()
@@ -75,5 +78,6 @@ var allCmd = &cobra.Command{
 		console.Info("Finished preparing Gutenberg Mobile PR")
 
 		console.Info("\nFinished preparing PRs:\n%s\n%s", gbPr.Url, pr.Url)
+		utils.FinishReport(reportComment)
 	},
 }
@@ -2,6 +2,7 @@ package prepare
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
@@ -12,7 +13,7 @@ var gbCmd = &cobra.Command{
 	Short: "prepare Gutenberg for a mobile release",
 	Long:  `Use this command to prepare a Gutenberg release PR`,
 	Run: func(cc *cobra.Command, args []string) {
-		preflight(args)
+		preflight(cc, "prepare-gb", args)
 
 		defer workspace.Cleanup()
 		build := release.Build{
@@ -25,6 +26,8 @@ var gbCmd = &cobra.Command{
 			},
 		}
 
+		useLocalCheckout(&build)
+
 		if version.IsPatchRelease() {
 			console.Info("Preparing a patch release")
 			tagName := "rnmobile/" + version.PriorVersion().String()
@@ -37,5 +40,6 @@ var gbCmd = &cobra.Command{
 		exitIfError(err, 1)
 
 		console.Info("Created PR %s", pr.Url)
+		utils.FinishReport(reportComment)
 	},
 }
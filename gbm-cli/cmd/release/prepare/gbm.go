@@ -2,6 +2,7 @@ package prepare
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
@@ -12,7 +13,7 @@ var gbmCmd = &cobra.Command{
 	Short: "prepare Gutenberg Mobile release",
 	Long:  `Use this command to prepare a Gutenberg Mobile release PR`,
 	Run: func(cmd *cobra.Command, args []string) {
-		preflight(args)
+		preflight(cmd, "prepare-gbm", args)
 		defer workspace.Cleanup()
 
 		console.Info("Preparing Gutenberg Mobile for release %s", version)
@@ -26,6 +27,8 @@ var gbmCmd = &cobra.Command{
 			Repo: "gutenberg-mobile",
 		}
 
+		useLocalCheckout(&build)
+
 		if version.IsPatchRelease() {
 			console.Info("Preparing a patch release")
 			tagName := version.PriorVersion().Vstring()
@@ -36,5 +39,6 @@ var gbmCmd = &cobra.Command{
 		exitIfError(err, 1)
 
 		console.Info("Created PR %s", pr.Url)
+		utils.FinishReport(reportComment)
 	},
 }
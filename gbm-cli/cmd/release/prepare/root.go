@@ -2,23 +2,30 @@ package prepare
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+	dr "github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/doctor"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
 	wp "github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/workspace"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/doctor"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gbm"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/semver"
 )
 
 var exitIfError func(error, int)
-var keepTempDir, noTag bool
+var keepTempDir, noTag, reportComment bool
 var workspace wp.Workspace
 var tempDir string
 var version semver.SemVer
 var prs []string
+var local map[string]string
 
 var PrepareCmd = &cobra.Command{
 	Use:   "prepare",
@@ -30,15 +37,26 @@ func Execute() {
 	exitIfError(err, 1)
 }
 
-// Set up the temp directory and version
+// Set up the temp directory, version and run report
 // Also validate Aztec versions
-func preflight(args []string) {
+func preflight(cc *cobra.Command, command string, args []string) {
 	var err error
-	tempDir = workspace.Dir()
-
 	version, err = utils.GetVersionArg(args)
 	exitIfError(err, 1)
 
+	rpos := map[string][]string{
+		"prepare-gb":  {repo.GutenbergRepo},
+		"prepare-gbm": {repo.GutenbergMobileRepo},
+		"prepare-all": {repo.GutenbergRepo, repo.GutenbergMobileRepo},
+	}[command]
+	exitIfError(dr.Preflight(func(dir string) []doctor.Check {
+		return doctor.Prepare(dir, rpos...)
+	}), 1)
+
+	exitIfError(workspace.Open(command, version.String()), 1)
+	utils.StartReport(cc, command, version.String())
+	tempDir = workspace.Dir()
+
 	// Validate Aztec version
 	if valid := gbm.ValidateAztecVersions(); !valid {
 		exitIfError(errors.New("invalid Aztec versions found"), 1)
@@ -46,6 +64,30 @@ func preflight(args []string) {
 	if keepTempDir {
 		workspace.Keep()
 	}
+
+	for rpo, path := range local {
+		if rpo != repo.GutenbergRepo && rpo != repo.GutenbergMobileRepo {
+			exitIfError(fmt.Errorf("unknown repo %s in --local, expected gutenberg or gutenberg-mobile", rpo), 1)
+		}
+		path, err := utils.ExpandHome(path)
+		exitIfError(err, 1)
+		local[rpo] = path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			exitIfError(fmt.Errorf("the local %s checkout %s is not a directory", rpo, path), 1)
+		}
+	}
+}
+
+// Points the build to the local checkout of its repo, if one was passed with --local
+func useLocalCheckout(build *release.Build) {
+	path, ok := local[build.Repo]
+	if !ok {
+		return
+	}
+	dir, err := filepath.Abs(path)
+	exitIfError(err, 1)
+	build.Dir = dir
+	build.Local = true
 }
 
 func init() {
@@ -65,6 +107,9 @@ func init() {
 	PrepareCmd.AddCommand(allCmd)
 	PrepareCmd.PersistentFlags().BoolVar(&keepTempDir, "keep", false, "Keep temporary directory after running command")
 	PrepareCmd.PersistentFlags().BoolVar(&noTag, "no-tag", false, "Prevent tagging the release. If not set, you will be prompted to tag the release")
+	PrepareCmd.PersistentFlags().StringVar(&repo.ForkOwner, "fork", repo.ForkOwner, "GitHub user to push the release branches to. The PRs are opened from the user's forks")
+	PrepareCmd.PersistentFlags().StringToStringVar(&local, "local", map[string]string{}, "Use existing checkouts instead of cloning, e.g. gutenberg=/path,gutenberg-mobile=/path")
+	PrepareCmd.PersistentFlags().BoolVar(&reportComment, "report-comment", false, "Post the run report as a collapsed comment on the created PRs")
 	PrepareCmd.PersistentFlags().StringSliceVar(&prs, "prs", []string{}, "prs to include in the release. Only used with patch releases")
 }
 
@@ -0,0 +1,74 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+)
+
+var RollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "close the PRs and delete the branches and tags of a release",
+	Long: `Use this command to undo a failed or aborted release. It finds what prepare and integrate created for the version:
+  - the Gutenberg, Gutenberg Mobile and integration PRs
+  - the release, integration and after branches
+  - the Gutenberg release tag
+
+Once confirmed, the open PRs are closed and the branches and tags are deleted. Merged PRs, the branches and tags
+of their repos and after branches that other PRs are based on are left alone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		semver, err := utils.GetVersionArg(args)
+		exitIfError(err, 1)
+		version := semver.String()
+
+		console.Info("Looking for the artifacts of release %s", version)
+		artifacts, err := release.FindArtifacts(version)
+		exitIfError(err, 1)
+
+		if len(artifacts) == 0 {
+			console.Info("Nothing to roll back for release %s", version)
+			return
+		}
+
+		console.Print(console.Heading, "\nRelease %s artifacts", version)
+		for _, a := range artifacts {
+			row := "• " + a.String()
+			if a.Url != "" {
+				row += " " + a.Url
+			}
+			if a.Blocked != "" {
+				row += fmt.Sprintf(" (skipped, %s)", a.Blocked)
+			}
+			console.Print(console.Row, row)
+		}
+
+		if !console.Confirm("rollback_release", fmt.Sprintf("\nClose the PRs and delete the branches and tags of release %s?", version)) {
+			console.Info("Bye 👋")
+			return
+		}
+
+		failed := 0
+		for _, a := range artifacts {
+			if a.Blocked != "" {
+				continue
+			}
+			if err := release.RollbackArtifact(a); err != nil {
+				console.Warn("Unable to roll back the %s: %v", a, err)
+				failed++
+				continue
+			}
+			if a.Kind == release.ArtifactPr {
+				console.Info("Closed %s", a.Url)
+			} else {
+				console.Info("Deleted the %s", a)
+			}
+		}
+
+		if failed > 0 {
+			exitIfError(fmt.Errorf("%d artifacts of release %s were not rolled back", failed, version), 1)
+		}
+	},
+}
@@ -16,15 +16,10 @@ var ReleaseCmd = &cobra.Command{
 	Short: "Release Gutenberg Mobile",
 }
 
+// The subcommands clean up their workspaces when they finish or exit early
 func Execute() {
 	err := ReleaseCmd.Execute()
 	exitIfError(err, 1)
-
-	if keepTempDir {
-		workspace.Keep()
-	}
-
-	defer workspace.Cleanup()
 }
 
 func init() {
@@ -32,5 +27,7 @@ func init() {
 	ReleaseCmd.AddCommand(prepare.PrepareCmd)
 	ReleaseCmd.AddCommand(IntegrateCmd)
 	ReleaseCmd.AddCommand(StatusCmd)
+	ReleaseCmd.AddCommand(AfterBranchesCmd)
+	ReleaseCmd.AddCommand(RollbackCmd)
 	ReleaseCmd.PersistentFlags().BoolVar(&keepTempDir, "keep", false, "Keep temporary directory after running command")
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gbm"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release/integrate"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
 )
 
@@ -66,6 +67,9 @@ var StatusCmd = &cobra.Command{
 			if err != nil {
 				console.Warn("Could not find Android PR: %s", err)
 			}
+			if watch {
+				markReady(integrate.AndroidIntegration{}, version, &androidPr)
+			}
 			androidPr.Repo = repo.GetOrg("WordPress-Android") + "/WordPress-Android"
 			prs = append(prs, androidPr)
 
@@ -73,6 +77,9 @@ var StatusCmd = &cobra.Command{
 			if err != nil {
 				console.Warn("Could not find iOS PR: %s", err)
 			}
+			if watch {
+				markReady(integrate.IosIntegration{}, version, &iosPr)
+			}
 			iosPr.Repo = repo.GetOrg("WordPress-iOS") + "/WordPress-iOS"
 			prs = append(prs, iosPr)
 
@@ -84,6 +91,8 @@ var StatusCmd = &cobra.Command{
 				if pr.Number == 0 {
 					pr.State = "…"
 					pr.Url = "…"
+				} else if pr.Draft {
+					pr.State = "draft"
 				}
 				console.Print(row, "• %-34s %-10s %-10v %s", pr.Repo, pr.State, pr.Mergeable, pr.Url)
 			}
@@ -125,8 +134,15 @@ var StatusCmd = &cobra.Command{
 	},
 }
 
+// Takes the integration PRs out of draft once they point to the published release
+func markReady(target integrate.Target, version string, pr *gh.PullRequest) {
+	if _, err := integrate.MarkReady(target, version, pr); err != nil {
+		console.Warn(err.Error())
+	}
+}
+
 func init() {
-	StatusCmd.Flags().BoolVar(&watch, "watch", false, "refresh the status every '-time' seconds")
+	StatusCmd.Flags().BoolVar(&watch, "watch", false, "refresh the status every '-time' seconds and mark the draft integration PRs ready for review once they point to the published release")
 
 	// Anything less than 5 seconds is too fast for the GH api
 	StatusCmd.Flags().IntVarP(&delay, "time", "t", 10, "delay in seconds between refreshes")
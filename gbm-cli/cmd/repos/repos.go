@@ -0,0 +1,73 @@
+package repos
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+var dryRun bool
+
+var ReposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Set up the repos the release PRs are opened on",
+}
+
+var setupLabelsCmd = &cobra.Command{
+	Use:   "setup-labels",
+	Short: "Create the labels of the release PRs",
+	Long: `Use this command to make sure the repos have the labels the release PRs are tagged with.
+The colors and descriptions are copied from the labels on the upstream repos, which are never recolored.
+Missing labels are created and labels with another color are updated. The repos are the ones of the configured orgs,
+e.g. your forks with GBM_WPMOBILE_ORG and GBM_WORDPRESS_ORG set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		changes := []release.LabelChange{}
+		for _, rpo := range release.LabelRepos {
+			existing, err := gh.ListLabels(rpo)
+			utils.ExitIfError(err, 1)
+			wanted, err := release.UpstreamLabels(rpo)
+			utils.ExitIfError(err, 1)
+			changes = append(changes, release.PlanLabels(rpo, existing, wanted)...)
+		}
+
+		if len(changes) == 0 {
+			console.Info("The labels are set up on all the repos")
+			return
+		}
+
+		console.Print(console.Heading, "\nLabel changes")
+		for _, c := range changes {
+			console.Print(console.Row, "• %s", c)
+		}
+		if dryRun {
+			return
+		}
+		if !console.Confirm("setup_labels", "\nApply the label changes?") {
+			console.Info("Bye 👋")
+			return
+		}
+
+		failed := 0
+		for _, c := range changes {
+			if err := release.ApplyLabelChange(c); err != nil {
+				console.Warn("Unable to apply %q on %s/%s: %v", c.Label.Name, repo.GetOrg(c.Repo), c.Repo, err)
+				failed++
+				continue
+			}
+			console.Info("%s", c)
+		}
+		if failed > 0 {
+			utils.ExitIfError(fmt.Errorf("%d label changes failed", failed), 1)
+		}
+	},
+}
+
+func init() {
+	ReposCmd.AddCommand(setupLabelsCmd)
+	setupLabelsCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the label changes")
+}
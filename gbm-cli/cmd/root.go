@@ -1,32 +1,103 @@
 package cmd
 
 import (
+	"path/filepath"
+
 	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/doctor"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/lifecycle"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/release"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/render"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/repos"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/workspace"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/mirror"
+	rel "github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/report"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
 )
 
 const Version = "v1.6.0"
 
+var answersFile, reviewersFile, logLevel, logFormat string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:     "gbm-cli",
 	Short:   "Gutenberg Mobile CLI",
 	Version: Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := console.SetLevel(logLevel); err != nil {
+			return err
+		}
+		if err := console.SetFormat(logFormat); err != nil {
+			return err
+		}
+		if reviewersFile != "" {
+			if err := rel.LoadReviewerRules(reviewersFile); err != nil {
+				return err
+			}
+		}
+		if answersFile != "" {
+			if err := console.LoadAnswers(answersFile); err != nil {
+				return err
+			}
+		}
+		// Checked once the flags are parsed so --yes, --no-input and the answers file apply to the update prompt
+		if !utils.CheckIfTempRun() {
+			utils.CheckExeVersion(Version)
+		}
+		return nil
+	},
 }
 
 func Execute() {
+	// Clean up the workspaces and stop the running commands however the command ends
+	lifecycle.HandleSignals()
+	console.ExitFunc = lifecycle.Exit
+
+	// Keep the output of every shell command in a log file for diagnosing failed runs
+	if dir, err := utils.LogDir(); err != nil {
+		console.Warn("Unable to find a directory for the log file: %v", err)
+	} else {
+		if err := utils.PruneLogs(dir, utils.LogMaxAge); err != nil {
+			console.Warn("Unable to prune the old logs: %v", err)
+		}
+		if err := workspace.PruneDebugLogs(utils.LogMaxAge); err != nil {
+			console.Warn("Unable to prune the old debug logs: %v", err)
+		}
+		name := filepath.Join(dir, utils.LogName())
+		if err := shell.OpenLog(name + ".log"); err != nil {
+			console.Warn("Unable to create the log file: %v", err)
+		} else if err := console.OpenDebugLog(name + ".debug.log"); err != nil {
+			console.Warn("Unable to create the debug log: %v", err)
+		}
+	}
+	defer console.CloseDebugLog()
+	// Deferred last so a panic and its cleanup are written to the debug log before it's closed
+	defer lifecycle.Recover()
+
 	err := rootCmd.Execute()
-	console.ExitIfError(err)
+	utils.ExitIfError(err, 1)
+	lifecycle.RunHooks()
 }
 
 func init() {
 	// Add the render command
 	rootCmd.AddCommand(render.RenderCmd)
 	rootCmd.AddCommand(release.ReleaseCmd)
-	if !utils.CheckIfTempRun() {
-		utils.CheckExeVersion(Version)
-	}
+	rootCmd.AddCommand(workspace.WorkspaceCmd)
+	rootCmd.AddCommand(doctor.DoctorCmd)
+	rootCmd.AddCommand(repos.ReposCmd)
+	rootCmd.PersistentFlags().BoolVar(&mirror.Enabled, "mirror-cache", mirror.Enabled, "Clone from mirrors kept in the user cache directory, fetching only what changed since the last run")
+	rootCmd.PersistentFlags().BoolVarP(&console.Yes, "yes", "y", false, "Answer yes to every confirmation without an answer in the answers file")
+	rootCmd.PersistentFlags().BoolVar(&console.NoInput, "no-input", false, "Fail on any prompt without an answer in the answers file instead of waiting for input")
+	rootCmd.PersistentFlags().BoolVar(&doctor.Skip, "skip-doctor", false, "Skip the preflight checks of prepare and integrate")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Lowest level of the messages printed: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format of the messages printed: text or json")
+	rootCmd.PersistentFlags().StringVar(&reviewersFile, "reviewers", "", "YAML `file` mapping repos to the users, teams and CODEOWNERS to request reviews from on the release PRs")
+	rootCmd.PersistentFlags().StringVar(&rel.ReleaseManager, "release-manager", "", "GitHub user assigned to the release PRs. Defaults to the user of the GitHub token")
+	rootCmd.PersistentFlags().StringVar(&answersFile, "answers", "", "YAML `file` mapping prompt IDs to answers, e.g. create_gb_tag: false")
+	report.CliVersion = Version
 }
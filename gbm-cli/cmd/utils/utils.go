@@ -7,10 +7,15 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/inconshreveable/go-update"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/lifecycle"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/report"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/semver"
 )
 
@@ -33,13 +38,102 @@ func ExitIfError(err error, code int) {
 	}
 }
 
+// Exit runs the deferred functions and the lifecycle hooks, then exits with the code
 func Exit(code int, deferred ...func()) {
-	os.Exit(func() int {
-		for _, d := range deferred {
-			d()
+	for _, d := range deferred {
+		d()
+	}
+	lifecycle.Exit(code)
+}
+
+// StartReport starts the run report of the command, with the flags set on the command line as inputs.
+// The report is archived when the command exits, whether it completed or not.
+func StartReport(cc *cobra.Command, command, version string) {
+	inputs := map[string]string{}
+	cc.Flags().Visit(func(f *pflag.Flag) {
+		inputs[f.Name] = f.Value.String()
+	})
+	report.Start(command, version, inputs)
+
+	lifecycle.OnExit("run report", lifecycle.SaveState, func() {
+		r, ok := report.Current()
+		if !ok {
+			return
+		}
+		path, err := r.Save()
+		if err != nil {
+			console.Warn("Unable to save the run report: %v", err)
+			return
+		}
+		console.Info("Saved the run report to %s", path)
+	})
+}
+
+// FinishReport marks the run report as completed and, if comment is set, posts it on the PRs of the run
+func FinishReport(comment bool) {
+	report.Finish()
+	if !comment {
+		return
+	}
+	r, _ := report.Current()
+	if err := r.Comment(); err != nil {
+		console.Warn("Unable to post the run report: %v", err)
+	}
+}
+
+// Returns the directory the per run logs are written to
+func LogDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "gbm-cli", "logs"), nil
+}
+
+// ExpandHome replaces a leading ~ with the home directory. The shell only expands it at the start of a word,
+// not in flag values such as gutenberg-mobile=~/src/gutenberg-mobile.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// LogMaxAge is how long the run logs are kept in the LogDir
+var LogMaxAge = 7 * 24 * time.Hour
+
+// LogName returns the base name of the log files of this run.
+// The pid keeps runs started in the same second from sharing their logs.
+func LogName() string {
+	return fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405.000"), os.Getpid())
+}
+
+// PruneLogs removes the log files older than maxAge from dir
+func PruneLogs(dir string, maxAge time.Duration) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".log" {
+			continue
 		}
-		return code
-	}())
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return fmt.Errorf("error removing the log %s: %v", e.Name(), err)
+		}
+	}
+	return nil
 }
 
 // Checks if running from a temp directory (go build)
@@ -83,7 +177,7 @@ func CheckExeVersion(version string) {
 	console.ExitIfError(err)
 
 	if latestRelease.TagName != version {
-		if console.Confirm("You are running an older version of the CLI. Would you like to update?") {
+		if console.Confirm("update_cli", "You are running an older version of the CLI. Would you like to update?") {
 
 			if url := exeDownloadUrl(latestRelease); url != "" {
 				if err := UpdateExe(url); err != nil {
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpandHome(t *testing.T) {
+	t.Run("It expands a leading tilde", func(t *testing.T) {
+		t.Setenv("HOME", "/home/wrangler")
+		for path, want := range map[string]string{
+			"~":                   "/home/wrangler",
+			"~/src/gutenberg":     "/home/wrangler/src/gutenberg",
+			"/src/gutenberg":      "/src/gutenberg",
+			"src/~/gutenberg":     "src/~/gutenberg",
+			"~wrangler/gutenberg": "~wrangler/gutenberg",
+		} {
+			got, err := ExpandHome(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("got %s, want %s for %s", got, want, path)
+			}
+		}
+	})
+}
+
+func TestPruneLogs(t *testing.T) {
+	t.Run("It removes the logs older than the max age", func(t *testing.T) {
+		dir := t.TempDir()
+		old := filepath.Join(dir, "old.debug.log")
+		recent := filepath.Join(dir, "recent.log")
+		other := filepath.Join(dir, "notes.txt")
+		for _, p := range []string{old, recent, other} {
+			if err := os.WriteFile(p, []byte("log"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		past := time.Now().Add(-48 * time.Hour)
+		for _, p := range []string{old, other} {
+			if err := os.Chtimes(p, past, past); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := PruneLogs(dir, 24*time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(old); !os.IsNotExist(err) {
+			t.Fatalf("Expected the old log to be removed, got %v", err)
+		}
+		for _, p := range []string{recent, other} {
+			if _, err := os.Stat(p); err != nil {
+				t.Fatalf("Expected %s to be kept, got %v", p, err)
+			}
+		}
+	})
+
+	t.Run("It ignores a missing directory", func(t *testing.T) {
+		if err := PruneLogs(filepath.Join(t.TempDir(), "missing"), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
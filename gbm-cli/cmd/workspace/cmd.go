@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/utils"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+)
+
+var cleanAll bool
+var olderThan time.Duration
+
+var WorkspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Inspect and prune the workspaces of previous runs",
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the workspaces",
+	Run: func(cmd *cobra.Command, args []string) {
+		infos, err := List()
+		utils.ExitIfError(err, 1)
+
+		if len(infos) == 0 {
+			console.Info("No workspaces found")
+			return
+		}
+
+		white := color.New(color.FgWhite).SprintFunc()
+		for _, i := range infos {
+			console.Print(console.HeadingRow, "%s", i.Name)
+			console.Print(console.Row, "Status: %s", white(status(i)))
+			console.Print(console.Row, "Created: %s", white(i.Created.Format(time.RFC1123)))
+			console.Print(console.Row, "Path: %s\n", white(i.Dir))
+		}
+	},
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open <name or version>",
+	Short: "Open a shell in a workspace",
+	Long:  `Opens $SHELL in the newest workspace matching the name or version. Exit the shell to return.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := Find(args[0])
+		utils.ExitIfError(err, 1)
+
+		if _, err := os.Stat(info.Dir); err != nil {
+			utils.ExitIfError(errors.New("the workspace directory no longer exists, remove it with `gbm-cli workspace clean`"), 1)
+		}
+
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		console.Info("Opening %s in %s, exit the shell to return", shell, info.Dir)
+		sh := exec.Command(shell)
+		sh.Dir = info.Dir
+		sh.Stdin = os.Stdin
+		sh.Stdout = os.Stdout
+		sh.Stderr = os.Stderr
+		if err := sh.Run(); err != nil {
+			console.Warn("The shell exited with %v", err)
+		}
+	},
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean [name or version]",
+	Short: "Remove workspaces",
+	Long:  `Removes the workspaces matching the name or version, or all of them with --all. Workspaces in use are never removed.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !cleanAll && olderThan == 0 {
+			utils.ExitIfError(errors.New("pass a name or version, --older-than or --all"), 1)
+		}
+
+		infos, err := List()
+		utils.ExitIfError(err, 1)
+
+		removed := 0
+		for _, i := range infos {
+			if len(args) == 1 && !i.Matches(args[0]) {
+				continue
+			}
+			if olderThan > 0 && time.Since(i.Created) < olderThan {
+				continue
+			}
+			if i.InUse() {
+				console.Warn("Skipping %s, it's in use", i.Name)
+				continue
+			}
+			console.Info("Removing %s", i.Dir)
+			if err := Remove(i); err != nil {
+				console.Error(err)
+				continue
+			}
+			removed++
+		}
+		console.Info("Removed %d workspace(s)", removed)
+	},
+}
+
+func status(i Info) string {
+	switch {
+	case i.InUse():
+		return "in use"
+	case i.Kept:
+		return "kept"
+	default:
+		// not cleaned up, e.g. the run crashed
+		return "abandoned"
+	}
+}
+
+func init() {
+	WorkspaceCmd.AddCommand(listCmd)
+	WorkspaceCmd.AddCommand(openCmd)
+	WorkspaceCmd.AddCommand(cleanCmd)
+	cleanCmd.Flags().BoolVar(&cleanAll, "all", false, "Remove all the workspaces that are not in use")
+	cleanCmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only remove workspaces created longer ago, e.g. 168h")
+}
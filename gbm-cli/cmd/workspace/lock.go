@@ -0,0 +1,215 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+)
+
+// Lock prevents concurrent runs on the same release version
+type Lock struct {
+	Version string
+	Command string
+	Pid     int
+	Started time.Time
+	Dir     string
+
+	path string
+}
+
+func lockPath(version string) (string, error) {
+	root, err := Root()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "locks", version+".lock"), nil
+}
+
+// Acquire locks the version for the command. Locks left behind by runs that
+// are no longer running are taken over.
+func Acquire(version, command string) (*Lock, error) {
+	path, err := lockPath(version)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	l := &Lock{Version: version, Command: command, Pid: os.Getpid(), Started: time.Now(), path: path}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	err = createLock(path, data)
+	if errors.Is(err, os.ErrExist) {
+		err = takeOver(version, path, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// createLock links a fully written temp file to path, which fails if a lock exists.
+// Other runs never see a partially written lock.
+func createLock(path string, data []byte) error {
+	tmp, err := writeTemp(path, data)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	return os.Link(tmp, path)
+}
+
+func writeTemp(path string, data []byte) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// takeOver replaces the lock at path if the run holding it is no longer running.
+// Take overs are serialized by a guard file and the lock is only removed while it's stale,
+// so two runs can't both end up holding it.
+func takeOver(version, path string, data []byte) error {
+	guard := path + ".takeover"
+	err := createGuard(guard)
+	if errors.Is(err, os.ErrExist) && staleGuard(guard) {
+		// Left behind by a run that crashed while taking over
+		console.Warn("Removing the stale take over guard of release %s", version)
+		if err := os.Remove(guard); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		err = createGuard(guard)
+	}
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("another run is taking over the lock of release %s, try again or remove %s if none is running", version, guard)
+	}
+	if err != nil {
+		return err
+	}
+	defer os.Remove(guard)
+
+	held, err := readLock(path)
+	switch {
+	case os.IsNotExist(err):
+		// Released meanwhile
+	case err == nil && processAlive(held.Pid):
+		return lockedError(held)
+	default:
+		// Locks are written atomically, one that can't be read is as stale as one of a stopped run
+		console.Warn("Removing the stale lock of release %s", version)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	err = createLock(path, data)
+	if errors.Is(err, os.ErrExist) {
+		if held, ok := Locked(version); ok {
+			return lockedError(held)
+		}
+		return fmt.Errorf("unable to lock release %s", version)
+	}
+	return err
+}
+
+// createGuard creates the guard with the pid of the run, failing if it exists
+func createGuard(path string) error {
+	g, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(g, os.Getpid())
+	if cerr := g.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// staleGuard reports whether the run holding the guard is no longer running.
+// A guard without a pid is only stale once it's old, its run may still be writing it.
+func staleGuard(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+		return !processAlive(pid)
+	}
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > time.Minute
+}
+
+func lockedError(held Lock) error {
+	return fmt.Errorf("release %s is locked by `%s` (pid %d) since %s. Wait for it to finish or stop it first",
+		held.Version, held.Command, held.Pid, held.Started.Format(time.Kitchen))
+}
+
+func readLock(path string) (Lock, error) {
+	l := Lock{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return l, err
+	}
+	return l, json.Unmarshal(data, &l)
+}
+
+// Locked returns the lock of the version if a running process holds it
+func Locked(version string) (Lock, bool) {
+	path, err := lockPath(version)
+	if err != nil {
+		return Lock{}, false
+	}
+	l, err := readLock(path)
+	if err != nil {
+		return Lock{}, false
+	}
+	return l, processAlive(l.Pid)
+}
+
+// setDir records the workspace of the run, replacing the lock atomically
+func (l *Lock) setDir(dir string) error {
+	l.Dir = dir
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	tmp, err := writeTemp(l.path, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Release removes the lock. It's safe to call on a nil lock.
+func (l *Lock) Release() {
+	if l == nil {
+		return
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		console.Error(err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package workspace
+
+import (
+	"os"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal 0 only checks that the process exists
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
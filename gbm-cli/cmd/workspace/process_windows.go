@@ -0,0 +1,31 @@
+//go:build windows
+
+package workspace
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// The exit code of processes that are still running
+const stillActive = 259
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// Handles can be opened on processes that exited, their exit code tells whether they are running
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Processes of other users can't be opened but are running
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,170 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Set GBM_WORKSPACE_DIR to keep the workspaces somewhere else than the user cache directory
+const RootEnv = "GBM_WORKSPACE_DIR"
+
+// Root returns the directory the workspaces are created in, e.g. ~/.cache/gbm-cli/workspaces
+func Root() (string, error) {
+	if dir := os.Getenv(RootEnv); dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "gbm-cli", "workspaces"), nil
+}
+
+// Info describes a workspace. It's saved next to the workspace directory, not in it,
+// since the repos are cloned straight into the directory.
+type Info struct {
+	Name    string
+	Command string
+	Version string
+	Dir     string
+	Created time.Time
+	Kept    bool
+}
+
+func infoPath(dir string) string {
+	return dir + ".json"
+}
+
+// DebugLogPath returns the debug log of the run that created the workspace.
+// It's kept when the workspace is cleaned up at the end of the run.
+func DebugLogPath(dir string) string {
+	return dir + ".log"
+}
+
+// PruneDebugLogs removes the debug logs older than maxAge of the workspaces that were cleaned up.
+// The logs of kept workspaces are removed with them, see Remove.
+func PruneDebugLogs(maxAge time.Duration) error {
+	root, err := Root()
+	if err != nil {
+		return err
+	}
+	paths, err := filepath.Glob(filepath.Join(root, "*.log"))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(infoPath(strings.TrimSuffix(p, ".log"))); err == nil {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing the debug log %s: %v", p, err)
+		}
+	}
+	return nil
+}
+
+func (i Info) save() error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(infoPath(i.Dir), data, 0644)
+}
+
+// InUse reports whether the run that created the workspace still holds the lock of the version
+func (i Info) InUse() bool {
+	lock, ok := Locked(i.Version)
+	return ok && lock.Dir == i.Dir
+}
+
+// Matches reports whether the name is the workspace name or its version.
+// Prefixes don't match, 1.11 would match 1.110.0 too.
+func (i Info) Matches(name string) bool {
+	return i.Name == name || i.Version == name
+}
+
+// List returns the workspaces, newest first
+func List() ([]Info, error) {
+	root, err := Root()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(root, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []Info{}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		info := Info{}
+		if err := json.Unmarshal(data, &info); err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", p, err)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Created.After(infos[j].Created)
+	})
+	return infos, nil
+}
+
+// Find returns the newest workspace with the given name or version
+func Find(name string) (Info, error) {
+	infos, err := List()
+	if err != nil {
+		return Info{}, err
+	}
+	for _, i := range infos {
+		if i.Matches(name) {
+			return i, nil
+		}
+	}
+	return Info{}, fmt.Errorf("no workspace matches %s, see `gbm-cli workspace list`", name)
+}
+
+// Remove deletes the workspace directory, its info and debug log
+func Remove(i Info) error {
+	if err := remove(i.Dir); err != nil {
+		return err
+	}
+	if err := os.Remove(DebugLogPath(i.Dir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func remove(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Remove(infoPath(dir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func markKept(dir string) error {
+	data, err := os.ReadFile(infoPath(dir))
+	if err != nil {
+		return err
+	}
+	info := Info{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+	info.Kept = true
+	return info.save()
+}
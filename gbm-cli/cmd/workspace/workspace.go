@@ -1,31 +1,40 @@
 package workspace
 
 import (
+	"fmt"
 	"os"
-	"os/signal"
 	"path"
+	"path/filepath"
+	"time"
 
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/cmd/lifecycle"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 )
 
 type Workspace interface {
+	Open(command, version string) error
 	Cleanup()
 	Dir() string
 	Keep()
 	create() error
-	setCleaner()
 }
 
 type workspace struct {
 	dir      string
 	keep     bool
-	prefix   string
-	cleaner  func()
+	command  string
+	version  string
 	disabled bool
+
+	// run the lifecycle hooks early, see lifecycle.OnExit
+	removeDir   func()
+	releaseLock func()
 }
 
+// NewWorkspace sets up a workspace. The directory is only created once the workspace
+// is opened for a command and version, see Open.
 func NewWorkspace() (Workspace, error) {
-	w := &workspace{prefix: "gbm-"}
+	w := &workspace{}
 	if _, noWorkspace := os.LookupEnv("GBM_NO_WORKSPACE"); noWorkspace {
 		console.Info("GBM_NO_WORKSPACE is set, not creating a workspace directory")
 		w.disabled = true
@@ -37,44 +46,72 @@ func NewWorkspace() (Workspace, error) {
 		w.dir = "."
 	}
 
+	return w, nil
+}
+
+// Open locks the release version and creates the workspace directory, named after the command and version.
+// It fails if another run is working on the same version.
+func (w *workspace) Open(command, version string) error {
+	w.command = command
+	w.version = version
+
+	lock, err := Acquire(version, command)
+	if err != nil {
+		return err
+	}
+	w.releaseLock = lifecycle.OnExit("release lock", lifecycle.ReleaseLocks, lock.Release)
+
 	if err := w.create(); err != nil {
-		return nil, err
+		w.releaseLock()
+		return err
 	}
-	w.setCleaner()
-	return w, nil
+	if w.dir != "" && !w.disabled {
+		w.removeDir = lifecycle.OnExit("workspace directory", lifecycle.RemoveDirs, w.clean)
+	}
+	return lock.setDir(w.dir)
 }
 
 func (w *workspace) create() error {
-	// if we're disabled, don't create a temp directory
+	// if we're disabled, don't create a directory
 	if w.disabled {
 		return nil
 	}
-	tempDir, err := os.MkdirTemp("", w.prefix)
+	root, err := Root()
 	if err != nil {
 		return err
 	}
-	w.dir = tempDir
+
+	info := Info{
+		Name:    fmt.Sprintf("%s-%s-%s", w.version, w.command, time.Now().Format("20060102-150405")),
+		Command: w.command,
+		Version: w.version,
+		Created: time.Now(),
+	}
+	info.Dir = filepath.Join(root, info.Name)
+	if err := os.MkdirAll(info.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := info.save(); err != nil {
+		return err
+	}
+	if err := console.OpenDebugLog(DebugLogPath(info.Dir)); err != nil {
+		console.Warn("Unable to move the debug log next to the workspace: %v", err)
+	}
+	w.dir = info.Dir
 	return nil
 }
 
-func (w *workspace) setCleaner() {
-	w.cleaner = func() {
-		if w.disabled || w.dir == "" || w.keep {
-			return
-		}
-
-		if err := os.RemoveAll(w.dir); err != nil {
+// Removes the workspace directory, or marks it as kept
+func (w *workspace) clean() {
+	if w.keep {
+		if err := markKept(w.dir); err != nil {
 			console.Error(err)
 		}
+		return
+	}
+	if err := remove(w.dir); err != nil {
+		console.Error(err)
 	}
-	// register a listener for ^C, call the cleanup function
-	go func() {
-		sigchan := make(chan os.Signal, 1)
-		signal.Notify(sigchan, os.Interrupt)
-		<-sigchan // wait for ^C
-		w.cleaner()
-		os.Exit(1)
-	}()
 }
 
 func (w *workspace) Dir() string {
@@ -90,10 +127,17 @@ func (w *workspace) Keep() {
 }
 
 func (w *workspace) Cleanup() {
-	if w.keep {
-		console.Info("Keeping temporary directory %s", w.dir)
-	} else {
-		console.Info("Cleaning up workspace directory %s", w.dir)
+	if !w.disabled && w.dir != "" {
+		if w.keep {
+			console.Info("Keeping workspace directory %s. Find it later with `gbm-cli workspace list`", w.dir)
+		} else {
+			console.Info("Cleaning up workspace directory %s", w.dir)
+		}
+	}
+	if w.removeDir != nil {
+		w.removeDir()
+	}
+	if w.releaseLock != nil {
+		w.releaseLock()
 	}
-	w.cleaner()
 }
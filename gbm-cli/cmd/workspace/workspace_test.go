@@ -0,0 +1,182 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkspace(t *testing.T) {
+	setup := func(t *testing.T) {
+		t.Helper()
+		t.Setenv(RootEnv, t.TempDir())
+		t.Setenv("CI", "")
+		os.Unsetenv("GBM_NO_WORKSPACE")
+	}
+
+	t.Run("It creates a named workspace and removes it on cleanup", func(t *testing.T) {
+		setup(t)
+		w, _ := NewWorkspace()
+		assertNoError(t, w.Open("prepare-gb", "1.109.0"))
+
+		infos, err := List()
+		assertNoError(t, err)
+		if len(infos) != 1 || !infos[0].Matches("1.109.0") || !infos[0].Matches(infos[0].Name) || !infos[0].InUse() {
+			t.Fatalf("Unexpected workspaces %+v", infos)
+		}
+
+		w.Cleanup()
+		if _, err := os.Stat(w.Dir()); !os.IsNotExist(err) {
+			t.Fatalf("Expected the workspace to be removed, got %v", err)
+		}
+		if _, locked := Locked("1.109.0"); locked {
+			t.Fatal("Expected the lock to be released")
+		}
+	})
+
+	t.Run("It keeps the workspace and lists it as kept", func(t *testing.T) {
+		setup(t)
+		w, _ := NewWorkspace()
+		assertNoError(t, w.Open("integrate", "1.109.0"))
+		w.Keep()
+		w.Cleanup()
+
+		info, err := Find("1.109.0")
+		assertNoError(t, err)
+		if !info.Kept || info.InUse() || info.Dir != w.Dir() {
+			t.Fatalf("Unexpected workspace %+v", info)
+		}
+		assertNoError(t, Remove(info))
+	})
+
+	t.Run("It only matches workspaces by their whole name or version", func(t *testing.T) {
+		i := Info{Name: "1.110.0-integrate-20231020-101500", Version: "1.110.0"}
+		for _, name := range []string{"1.11", "1.110", "1.110.0-integrate"} {
+			if i.Matches(name) {
+				t.Fatalf("Expected %s not to match %s", name, i.Name)
+			}
+		}
+	})
+
+	t.Run("It prunes the old debug logs of the cleaned up workspaces", func(t *testing.T) {
+		setup(t)
+		root, _ := Root()
+		assertNoError(t, os.MkdirAll(root, os.ModePerm))
+		cleaned := filepath.Join(root, "1.108.0-integrate-20231020-101500.log")
+		kept := filepath.Join(root, "1.109.0-integrate-20231020-101500.log")
+		recent := filepath.Join(root, "1.110.0-integrate-20231020-101500.log")
+		for _, p := range []string{cleaned, kept, recent} {
+			assertNoError(t, os.WriteFile(p, nil, 0600))
+		}
+		assertNoError(t, os.WriteFile(strings.TrimSuffix(kept, ".log")+".json", []byte("{}"), 0644))
+		past := time.Now().Add(-48 * time.Hour)
+		assertNoError(t, os.Chtimes(cleaned, past, past))
+		assertNoError(t, os.Chtimes(kept, past, past))
+
+		assertNoError(t, PruneDebugLogs(24*time.Hour))
+
+		if _, err := os.Stat(cleaned); !os.IsNotExist(err) {
+			t.Fatalf("Expected the old log to be removed, got %v", err)
+		}
+		for _, p := range []string{kept, recent} {
+			if _, err := os.Stat(p); err != nil {
+				t.Fatalf("Expected %s to be kept, got %v", p, err)
+			}
+		}
+	})
+
+	t.Run("It prevents concurrent runs on the same version", func(t *testing.T) {
+		setup(t)
+		w, _ := NewWorkspace()
+		assertNoError(t, w.Open("prepare-all", "1.109.0"))
+		defer w.Cleanup()
+
+		other, _ := NewWorkspace()
+		if err := other.Open("integrate", "1.109.0"); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+
+		// other versions are not locked
+		another, _ := NewWorkspace()
+		assertNoError(t, another.Open("integrate", "1.110.0"))
+		another.Cleanup()
+	})
+
+	t.Run("It takes over stale locks", func(t *testing.T) {
+		setup(t)
+		path, _ := lockPath("1.109.0")
+		assertNoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		// a pid that can't be running
+		assertNoError(t, os.WriteFile(path, []byte(`{"Version":"1.109.0","Pid":-1}`), 0644))
+
+		lock, err := Acquire("1.109.0", "prepare-gb")
+		assertNoError(t, err)
+		lock.Release()
+	})
+
+	t.Run("It does not take over a lock another run is taking over", func(t *testing.T) {
+		setup(t)
+		path, _ := lockPath("1.109.0")
+		assertNoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assertNoError(t, os.WriteFile(path, []byte(`{"Version":"1.109.0","Pid":-1}`), 0644))
+		assertNoError(t, os.WriteFile(path+".takeover", []byte(strconv.Itoa(os.Getpid())), 0644))
+
+		if _, err := Acquire("1.109.0", "prepare-gb"); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		data, err := os.ReadFile(path)
+		assertNoError(t, err)
+		if string(data) != `{"Version":"1.109.0","Pid":-1}` {
+			t.Fatalf("Expected the stale lock to be left alone, got %s", data)
+		}
+	})
+
+	t.Run("It removes the take over guard of a run that is no longer running", func(t *testing.T) {
+		setup(t)
+		path, _ := lockPath("1.109.0")
+		assertNoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assertNoError(t, os.WriteFile(path, []byte(`{"Version":"1.109.0","Pid":-1}`), 0644))
+		assertNoError(t, os.WriteFile(path+".takeover", []byte("-1"), 0644))
+
+		l, err := Acquire("1.109.0", "prepare-gb")
+		assertNoError(t, err)
+		defer l.Release()
+		if _, err := os.Stat(path + ".takeover"); !os.IsNotExist(err) {
+			t.Fatalf("Expected the guard to be removed, got %v", err)
+		}
+	})
+
+	t.Run("It only lets one of the concurrent runs take over a stale lock", func(t *testing.T) {
+		setup(t)
+		path, _ := lockPath("1.109.0")
+		assertNoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assertNoError(t, os.WriteFile(path, []byte(`{"Version":"1.109.0","Pid":-1}`), 0644))
+
+		results := make(chan error, 8)
+		for i := 0; i < cap(results); i++ {
+			go func() {
+				_, err := Acquire("1.109.0", "prepare-gb")
+				results <- err
+			}()
+		}
+		acquired := 0
+		for i := 0; i < cap(results); i++ {
+			if err := <-results; err == nil {
+				acquired++
+			}
+		}
+		if acquired != 1 {
+			t.Fatalf("Expected a single run to take over the lock, got %d", acquired)
+		}
+	})
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -12,6 +12,7 @@ require (
 	github.com/mikefarah/yq/v4 v4.35.2
 	github.com/spf13/cobra v1.7.0
 	golang.design/x/clipboard v0.7.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -42,14 +43,13 @@ require (
 	golang.org/x/text v0.13.0 // indirect
 	golang.org/x/xerrors v0.0.0-20220609144429-65e65417b02f // indirect
 	gopkg.in/op/go-logging.v1 v1.0.0-20160211212156-b2cb9fa56473 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 require (
 	github.com/cli/go-gh/v2 v2.4.0
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/spf13/pflag v1.0.5
 	golang.org/x/image v0.13.0 // indirect
 	golang.org/x/mobile v0.0.0-20231006135142-2b44d11868fe // indirect
-	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/sys v0.13.0
 )
@@ -3,6 +3,7 @@ package console
 import (
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
@@ -49,9 +50,12 @@ func Exit(code int, format string, args ...interface{}) {
 	red := color.New(color.FgRed).SprintfFunc()
 	l.Printf(red("\n"+format, args...))
 	color.Unset()
-	os.Exit(1)
+	ExitFunc(code)
 }
 
+// ExitFunc exits the process. The CLI replaces it to run its cleanup hooks first.
+var ExitFunc = os.Exit
+
 func Clipboard(m string) {
 	clipboard.Write(clipboard.FmtText, []byte(m))
 }
@@ -68,32 +72,36 @@ func Out(m string) {
 Use Info to log messages from the scripts. Output is sent to stderr to not muddle up pipe-able output
 */
 func Info(format string, args ...interface{}) {
-	cyan := color.New(color.FgCyan).SprintfFunc()
-	l.Printf(cyan("[INFO] "+format, args...))
-	color.Unset()
+	emit(slog.LevelInfo, color.New(color.FgCyan), "[INFO] ", fmt.Sprintf(format, args...))
 }
 
 func Log(format string, args ...interface{}) {
-	l.Printf(format+"\n", args...)
-	color.Unset()
+	emit(slog.LevelInfo, nil, "", fmt.Sprintf(format, args...))
 }
 
 func Debug(format string, args ...interface{}) {
-	blue := color.New(color.FgHiBlue).SprintfFunc()
-	l.Printf(blue("[DEBUG] "+format, args...))
-	color.Unset()
+	emit(slog.LevelDebug, color.New(color.FgHiBlue), "[DEBUG] ", fmt.Sprintf(format, args...))
 }
 
+// Print prints styled output, e.g. headings and rows. It's shown whatever the log level.
 func Print(c *color.Color, format string, args ...interface{}) {
-	styled := c.SprintfFunc()
-	l.Printf(styled(format, args...))
+	msg := fmt.Sprintf(format, args...)
+	debugMu.Lock()
+	if debugLog != nil {
+		debugLog.Info(strings.TrimSpace(msg))
+	}
+	debugMu.Unlock()
+
+	if logFormat == "json" {
+		printLogger.Info(strings.TrimSpace(msg))
+		return
+	}
+	l.Print(c.Sprint(msg))
 	color.Unset()
 }
 
 func Warn(format string, args ...interface{}) {
-	yellow := color.New(color.FgYellow).SprintfFunc()
-	l.Printf(yellow("[WARN] "+format, args...))
-	color.Unset()
+	emit(slog.LevelWarn, color.New(color.FgYellow), "[WARN] ", fmt.Sprintf(format, args...))
 }
 
 func Inspect(i interface{}) {
@@ -107,40 +115,5 @@ func Clear() {
 }
 
 func Error(err error) {
-	red := color.New(color.FgRed).SprintfFunc()
-	l.Printf(red("[ERROR] " + err.Error() + "\n"))
-	color.Unset()
-}
-
-func Confirm(ask string) bool {
-	// If not in a tty (CI) return true
-	if os.Getenv("CI") == "true" {
-		return true
-	}
-	var response string
-	fmt.Print(Highlight.Sprintf("%s [y/n]: ", ask))
-
-	_, err := fmt.Scanln(&response)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	response = strings.ToLower(strings.TrimSpace(response))
-
-	if response == "y" || response == "yes" {
-		return true
-	}
-	return false
-}
-
-func Ask(ask string) string {
-	var response string
-	fmt.Print(Highlight.Sprintf("%s: ", ask))
-
-	_, err := fmt.Scanln(&response)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	return strings.TrimSpace(response)
+	emit(slog.LevelError, color.New(color.FgRed), "[ERROR] ", err.Error()+"\n")
 }
@@ -0,0 +1,137 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+var (
+	level     = new(slog.LevelVar)
+	logFormat = "text"
+
+	// Logs as json lines to stderr with --log-format json
+	jsonLogger *slog.Logger
+	// Prints the output of Print as json lines, whatever the level
+	printLogger *slog.Logger
+
+	debugMu   sync.Mutex
+	debugFile *os.File
+	debugLog  *slog.Logger
+)
+
+// SetLevel sets the lowest level printed to stderr: debug, info, warn or error.
+// The debug log always gets every level.
+func SetLevel(l string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(l)); err != nil {
+		return fmt.Errorf("invalid log level %s, expected debug, info, warn or error", l)
+	}
+	level.Set(lvl)
+	return nil
+}
+
+// SetFormat sets how messages are printed to stderr: text or json
+func SetFormat(f string) error {
+	if f != "text" && f != "json" {
+		return fmt.Errorf("invalid log format %s, expected text or json", f)
+	}
+	logFormat = f
+	jsonLogger = slog.New(slog.NewJSONHandler(l.Writer(), &slog.HandlerOptions{Level: level}))
+	printLogger = slog.New(slog.NewJSONHandler(l.Writer(), nil))
+	return nil
+}
+
+// OpenDebugLog writes every message and event of the run as json lines to the file at path.
+// If a debug log is already open, what was logged so far is moved to the new file.
+func OpenDebugLog(path string) error {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+
+	// Only the user can read the log, it has the details of every command
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if debugFile != nil {
+		prev := debugFile.Name()
+		debugFile.Close()
+		if data, err := os.ReadFile(prev); err == nil {
+			f.Write(data)
+			os.Remove(prev)
+		}
+	}
+	debugFile = f
+	debugLog = slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return nil
+}
+
+// DebugLogPath returns the path of the debug log, if one is open
+func DebugLogPath() string {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	if debugFile == nil {
+		return ""
+	}
+	return debugFile.Name()
+}
+
+// CloseDebugLog stops writing to the debug log
+func CloseDebugLog() {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	if debugFile != nil {
+		debugFile.Close()
+	}
+	debugFile = nil
+	debugLog = nil
+}
+
+// Record logs an event of the run, e.g. a GitHub request or a shell command, with its attributes as key value pairs.
+// Events are always in the debug log and only printed with --log-level debug.
+func Record(msg string, args ...interface{}) {
+	emit(slog.LevelDebug, color.New(color.FgHiBlue), "[DEBUG] ", msg, args...)
+}
+
+// emit prints the message to stderr if the level is enabled and writes it to the debug log.
+// In text format the message is printed in color with the prefix, e.g. [INFO].
+func emit(lvl slog.Level, c *color.Color, prefix, msg string, args ...interface{}) {
+	msg = strings.TrimRight(msg, "\n")
+
+	debugMu.Lock()
+	if debugLog != nil {
+		debugLog.Log(context.Background(), lvl, strings.TrimSpace(msg), args...)
+	}
+	debugMu.Unlock()
+
+	if lvl < level.Level() {
+		return
+	}
+	if logFormat == "json" {
+		jsonLogger.Log(context.Background(), lvl, strings.TrimSpace(msg), args...)
+		return
+	}
+
+	line := prefix + msg
+	if len(args) > 0 {
+		line += " " + formatAttrs(args)
+	}
+	if c != nil {
+		line = c.Sprint(line)
+	}
+	l.Println(line)
+	color.Unset()
+}
+
+func formatAttrs(args []interface{}) string {
+	pairs := []string{}
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", args[i], args[i+1]))
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,164 @@
+package console
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestLog(t *testing.T) {
+	setup := func(t *testing.T, lvl, f string) *bytes.Buffer {
+		t.Helper()
+		out := &bytes.Buffer{}
+		prev := l.Writer()
+		l.SetOutput(out)
+		noColor := color.NoColor
+		color.NoColor = true
+		t.Cleanup(func() {
+			l.SetOutput(prev)
+			color.NoColor = noColor
+			SetLevel("info")
+			SetFormat("text")
+			CloseDebugLog()
+		})
+
+		if err := SetLevel(lvl); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := SetFormat(f); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return out
+	}
+
+	readLines := func(t *testing.T, path string) []map[string]interface{} {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := []map[string]interface{}{}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			entry := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("Invalid json line %q: %v", line, err)
+			}
+			lines = append(lines, entry)
+		}
+		return lines
+	}
+
+	t.Run("It prints the messages at or above the level", func(t *testing.T) {
+		out := setup(t, "warn", "text")
+
+		Info("cloning %s", "gutenberg")
+		Debug("details")
+		Warn("branch exists")
+		Error(errors.New("failed"))
+
+		want := "[WARN] branch exists\n[ERROR] failed\n"
+		if got := out.String(); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("It prints events with their attributes at the debug level", func(t *testing.T) {
+		out := setup(t, "debug", "text")
+
+		Record("shell command", "cmd", "git status", "exit", 0)
+
+		want := "[DEBUG] shell command cmd=git status exit=0\n"
+		if got := out.String(); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("It prints json lines", func(t *testing.T) {
+		out := setup(t, "info", "json")
+
+		Info("cloning %s", "gutenberg")
+		Record("github request", "status", 200)
+
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+			t.Fatalf("Expected a single json line, got %q", out.String())
+		}
+		if entry["level"] != "INFO" || entry["msg"] != "cloning gutenberg" {
+			t.Fatalf("got %v", entry)
+		}
+	})
+
+	t.Run("It prints the styled output whatever the level", func(t *testing.T) {
+		for _, f := range []string{"text", "json"} {
+			out := setup(t, "error", f)
+
+			Print(Heading, "Release 1.110.0 artifacts")
+
+			if !strings.Contains(out.String(), "Release 1.110.0 artifacts") {
+				t.Fatalf("Expected the heading in the %s output, got %q", f, out.String())
+			}
+		}
+	})
+
+	t.Run("It writes every level to the debug log", func(t *testing.T) {
+		setup(t, "error", "text")
+		path := filepath.Join(t.TempDir(), "debug.log")
+		if err := OpenDebugLog(path); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		Info("cloning")
+		Record("prompt answer", "id", "create_gb_tag", "value", "false")
+
+		lines := readLines(t, path)
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 lines, got %d", len(lines))
+		}
+		if lines[1]["msg"] != "prompt answer" || lines[1]["id"] != "create_gb_tag" {
+			t.Fatalf("got %v", lines[1])
+		}
+	})
+
+	t.Run("It moves the debug log", func(t *testing.T) {
+		setup(t, "info", "text")
+		dir := t.TempDir()
+		first := filepath.Join(dir, "first.log")
+		second := filepath.Join(dir, "second.log")
+
+		if err := OpenDebugLog(first); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		Info("before")
+		if err := OpenDebugLog(second); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		Info("after")
+
+		if _, err := os.Stat(first); !os.IsNotExist(err) {
+			t.Fatal("Expected the first log to be removed")
+		}
+		if got := len(readLines(t, second)); got != 2 {
+			t.Fatalf("Expected 2 lines, got %d", got)
+		}
+		if DebugLogPath() != second {
+			t.Fatalf("got %s", DebugLogPath())
+		}
+	})
+
+	t.Run("It rejects unknown levels and formats", func(t *testing.T) {
+		setup(t, "info", "text")
+
+		if err := SetLevel("verbose"); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if err := SetFormat("xml"); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// Yes answers yes to every confirmation that has no answer in the answers file
+	Yes bool
+
+	// NoInput fails any prompt that has no answer instead of reading stdin
+	NoInput bool
+
+	answersMu sync.Mutex
+	answers   = map[string]string{}
+	answered  []Answer
+	stdin     = bufio.NewReader(os.Stdin)
+)
+
+// Answer is how a prompt was answered
+type Answer struct {
+	ID     string
+	Prompt string
+	Value  string
+	Source string
+}
+
+// LoadAnswers reads a YAML file mapping prompt IDs to answers, e.g.
+//
+//	create_gb_tag: false
+//	editor: skip
+func LoadAnswers(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	parsed := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("error parsing the answers file %s: %v", path, err)
+	}
+
+	answersMu.Lock()
+	defer answersMu.Unlock()
+	for id, v := range parsed {
+		answers[id] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+// Answered returns the prompts answered so far
+func Answered() []Answer {
+	answersMu.Lock()
+	defer answersMu.Unlock()
+	return append([]Answer{}, answered...)
+}
+
+// HasAnswer reports whether the answers file has an answer for the prompt
+func HasAnswer(id string) bool {
+	_, ok := lookup(id)
+	return ok
+}
+
+func interactive() bool {
+	return !NoInput && !Yes && os.Getenv("CI") != "true"
+}
+
+func lookup(id string) (string, bool) {
+	answersMu.Lock()
+	defer answersMu.Unlock()
+	v, ok := answers[id]
+	return v, ok
+}
+
+func record(a Answer) {
+	Record("prompt answer", "id", a.ID, "value", a.Value, "source", a.Source)
+	answersMu.Lock()
+	defer answersMu.Unlock()
+	answered = append(answered, a)
+}
+
+// Confirm asks a yes/no question. The id identifies the prompt in the answers file.
+func Confirm(id, ask string) bool {
+	return confirm(id, ask, true)
+}
+
+// ConfirmExplicit is like Confirm for destructive steps, e.g. overwriting a remote branch.
+// --yes and CI don't answer it, it needs an answer in the answers file or from stdin.
+func ConfirmExplicit(id, ask string) bool {
+	return confirm(id, ask, false)
+}
+
+func confirm(id, ask string, autoYes bool) bool {
+	if v, ok := lookup(id); ok {
+		yes, err := strconv.ParseBool(normalizeBool(v))
+		if err != nil {
+			fail("invalid answer %q for %s, expected true or false", v, id)
+			return false
+		}
+		Info("%s [%s: %t]", strings.TrimSpace(ask), id, yes)
+		record(Answer{ID: id, Prompt: ask, Value: strconv.FormatBool(yes), Source: "answers file"})
+		return yes
+	}
+
+	if !interactive() {
+		// CI used to answer yes to everything, keep doing so
+		if autoYes && (Yes || os.Getenv("CI") == "true") {
+			Info("%s [%s: true]", strings.TrimSpace(ask), id)
+			record(Answer{ID: id, Prompt: ask, Value: "true", Source: "--yes"})
+			return true
+		}
+		// --yes alone leaves the explicit confirmations to stdin
+		if NoInput || os.Getenv("CI") == "true" {
+			fail("%s needs an answer, add `%s: true` or `%s: false` to the answers file", id, id, id)
+			return false
+		}
+	}
+
+	fmt.Print(Highlight.Sprintf("%s [y/n]: ", ask))
+	response := strings.ToLower(readLine())
+	yes := response == "y" || response == "yes"
+	record(Answer{ID: id, Prompt: ask, Value: strconv.FormatBool(yes), Source: "stdin"})
+	return yes
+}
+
+// Ask asks for a value. The id identifies the prompt in the answers file.
+func Ask(id, ask string) string {
+	if v, ok := lookup(id); ok {
+		Info("%s [%s: %s]", strings.TrimSpace(ask), id, v)
+		record(Answer{ID: id, Prompt: ask, Value: v, Source: "answers file"})
+		return v
+	}
+	if !interactive() {
+		fail("%s needs an answer, add `%s: <value>` to the answers file", id, id)
+		return ""
+	}
+
+	fmt.Print(Highlight.Sprintf("%s: ", ask))
+	response := readLine()
+	record(Answer{ID: id, Prompt: ask, Value: response, Source: "stdin"})
+	return response
+}
+
+// AskOr is like Ask but returns fallback when there is no one to answer because of --yes or CI,
+// e.g. to skip an optional step. --no-input still fails the prompt.
+func AskOr(id, ask, fallback string) string {
+	if _, ok := lookup(id); !ok && !interactive() && !NoInput {
+		Info("%s [%s: %s]", strings.TrimSpace(ask), id, fallback)
+		record(Answer{ID: id, Prompt: ask, Value: fallback, Source: "--yes"})
+		return fallback
+	}
+	return Ask(id, ask)
+}
+
+// Logs the error and exits, prompts can't go on without an answer
+func fail(format string, args ...interface{}) {
+	Error(fmt.Errorf(format, args...))
+	ExitFunc(1)
+}
+
+// Accepts the yaml 1.1 booleans too, e.g. yes and no
+func normalizeBool(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "yes", "y", "on":
+		return "true"
+	case "no", "n", "off":
+		return "false"
+	}
+	return v
+}
+
+func readLine() string {
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		fail("unable to read the answer: %v", err)
+	}
+	return strings.TrimSpace(line)
+}
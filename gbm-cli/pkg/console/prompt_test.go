@@ -0,0 +1,122 @@
+package console
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrompts(t *testing.T) {
+	setup := func(t *testing.T, yaml string) {
+		t.Helper()
+		t.Setenv("CI", "")
+		answers = map[string]string{}
+		answered = nil
+		Yes, NoInput = false, false
+		t.Cleanup(func() { Yes, NoInput = false, false })
+
+		if yaml != "" {
+			path := filepath.Join(t.TempDir(), "answers.yml")
+			if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := LoadAnswers(path); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	}
+
+	// ExitError panics instead of exiting so the test can check it was called
+	exits := func(t *testing.T, f func()) (exited bool) {
+		t.Helper()
+		prev := ExitFunc
+		ExitFunc = func(int) { panic("exit") }
+		defer func() {
+			ExitFunc = prev
+			exited = recover() == "exit"
+		}()
+		f()
+		return false
+	}
+
+	t.Run("It answers from the answers file", func(t *testing.T) {
+		setup(t, "create_gb_tag: false\ncreate_gb_pr: yes\neditor: code --wait\n")
+
+		if Confirm("create_gb_tag", "Tag?") {
+			t.Fatal("Expected create_gb_tag to be false")
+		}
+		if !Confirm("create_gb_pr", "Create the PR?") {
+			t.Fatal("Expected create_gb_pr to be true")
+		}
+		if got := Ask("editor", "Editor?"); got != "code --wait" {
+			t.Fatalf("got %q", got)
+		}
+		if got := len(Answered()); got != 3 {
+			t.Fatalf("Expected 3 recorded answers, got %d", got)
+		}
+	})
+
+	t.Run("It answers yes to the other confirmations with --yes", func(t *testing.T) {
+		setup(t, "create_gb_tag: false\n")
+		Yes = true
+
+		if !Confirm("create_gb_pr", "Create the PR?") {
+			t.Fatal("Expected yes")
+		}
+		if Confirm("create_gb_tag", "Tag?") {
+			t.Fatal("Expected the answers file to win over --yes")
+		}
+		if !exits(t, func() { Ask("editor", "Editor?") }) {
+			t.Fatal("Expected asking without an answer to exit")
+		}
+		if got := AskOr("editor", "Editor?", "skip"); got != "skip" {
+			t.Fatalf("Expected the fallback, got %q", got)
+		}
+	})
+
+	t.Run("It fails the prompts without answers with --no-input", func(t *testing.T) {
+		setup(t, "")
+		NoInput = true
+
+		if !exits(t, func() { Confirm("create_gb_pr", "Create the PR?") }) {
+			t.Fatal("Expected the confirmation to exit")
+		}
+		if !exits(t, func() { AskOr("editor", "Editor?", "skip") }) {
+			t.Fatal("Expected asking without an answer to exit")
+		}
+	})
+
+	t.Run("It fails on invalid answers", func(t *testing.T) {
+		setup(t, "create_gb_pr: maybe\n")
+
+		if !exits(t, func() { Confirm("create_gb_pr", "Create the PR?") }) {
+			t.Fatal("Expected the confirmation to exit")
+		}
+	})
+
+	t.Run("It doesn't answer the explicit confirmations with --yes or CI", func(t *testing.T) {
+		setup(t, "")
+		t.Setenv("CI", "true")
+		Yes = true
+
+		if !exits(t, func() { ConfirmExplicit("overwrite_remote_branch", "Overwrite?") }) {
+			t.Fatal("Expected the confirmation to exit in CI")
+		}
+
+		t.Setenv("CI", "")
+		prev := stdin
+		t.Cleanup(func() { stdin = prev })
+		stdin = bufio.NewReader(strings.NewReader("n\n"))
+		if ConfirmExplicit("overwrite_remote_branch", "Overwrite?") {
+			t.Fatal("Expected the answer from stdin with --yes")
+		}
+
+		setup(t, "overwrite_remote_branch: true\n")
+		t.Setenv("CI", "true")
+		if !ConfirmExplicit("overwrite_remote_branch", "Overwrite?") {
+			t.Fatal("Expected the answer from the answers file")
+		}
+	})
+}
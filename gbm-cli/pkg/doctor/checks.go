@@ -0,0 +1,52 @@
+package doctor
+
+import (
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+// SpacePerRepo is roughly what a clone takes once its dependencies are installed
+const SpacePerRepo uint64 = 5 << 30
+
+// github returns the checks of the GitHub access the commands need on the repos
+func github(rpos []string) []Check {
+	checks := []Check{GitHubAuth}
+	for _, rpo := range rpos {
+		checks = append(checks, PushPermission(rpo))
+		for _, label := range release.LabelsFor(rpo) {
+			checks = append(checks, LabelExists(rpo, label))
+		}
+	}
+	return checks
+}
+
+// Prepare returns the checks for preparing the release of the repos in dir
+func Prepare(dir string, rpos ...string) []Check {
+	checks := append(github(rpos), Tool("git"), Node)
+	for _, rpo := range rpos {
+		// The Gutenberg preios script runs bundle install and pod install
+		if rpo == repo.GutenbergRepo {
+			checks = append(checks, Tool("bundle"), Tool("pod"))
+		}
+	}
+	return append(checks, DiskSpace(dir, SpacePerRepo*uint64(len(rpos))))
+}
+
+// Integrate returns the checks for integrating the release into the host apps in dir
+func Integrate(dir string, skipDeps bool, rpos ...string) []Check {
+	checks := append(github(rpos), Tool("git"))
+	for _, rpo := range rpos {
+		// The iOS dependency steps run bundle install and rake dependencies
+		if rpo == repo.WordPressIosRepo && !skipDeps {
+			checks = append(checks, Tool("bundle"), Tool("rake"))
+		}
+	}
+	return append(checks, DiskSpace(dir, SpacePerRepo*uint64(len(rpos))))
+}
+
+// All returns every check, for all the repos
+func All(dir string) []Check {
+	rpos := []string{repo.GutenbergRepo, repo.GutenbergMobileRepo, repo.WordPressAndroidRepo, repo.WordPressIosRepo}
+	checks := append(github(rpos), Tool("git"), Node, Tool("bundle"), Tool("pod"), Tool("rake"))
+	return append(checks, DiskSpace(dir, SpacePerRepo*uint64(len(rpos))))
+}
@@ -0,0 +1,259 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+type Status int
+
+const (
+	Ok Status = iota
+	Warning
+	Failed
+)
+
+// Result is the outcome of a check. Fix tells how to solve a warning or failure.
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+// Check checks one requirement of the release commands
+type Check func() Result
+
+// Run runs the checks in order
+func Run(checks []Check) []Result {
+	results := []Result{}
+	for _, c := range checks {
+		results = append(results, c())
+	}
+	return results
+}
+
+// HasFailures reports whether any of the checks failed
+func HasFailures(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// GitHubAuth checks there is a GitHub token and that it can access the repos.
+// Classic tokens need the repo scope, or public_repo since the release repos are public.
+func GitHubAuth() Result {
+	r := Result{Name: "GitHub authentication"}
+	source := gh.Auth()
+	if source == "" {
+		r.Status = Failed
+		r.Detail = "no GitHub token found"
+		r.Fix = "Run `gh auth login` or export a token as GH_TOKEN"
+		return r
+	}
+
+	user, scopes, ok, err := gh.TokenScopes()
+	if err != nil {
+		r.Status = Failed
+		r.Detail = fmt.Sprintf("the token from %s was rejected: %v", source, err)
+		r.Fix = "Run `gh auth refresh` or create a new token"
+		return r
+	}
+	r.Detail = fmt.Sprintf("logged in as %s with the token from %s", user.Login, source)
+	if !ok {
+		r.Detail += ", the token doesn't report its scopes"
+		return r
+	}
+	if !hasScope(scopes, "repo", "public_repo") {
+		r.Status = Failed
+		r.Detail += fmt.Sprintf(", missing the repo scope (has %s)", strings.Join(scopes, ", "))
+		r.Fix = "Run `gh auth refresh --scopes repo` or create a token with the repo scope"
+	}
+	return r
+}
+
+func hasScope(scopes []string, wanted ...string) bool {
+	for _, s := range scopes {
+		for _, w := range wanted {
+			if s == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PushPermission checks the branches can be pushed to the repo, or to the fork when repo.ForkOwner is set
+func PushPermission(rpo string) Check {
+	return func() Result {
+		org := repo.GetOrg(rpo)
+		if repo.ForkOwner != "" {
+			org = repo.ForkOwner
+		}
+		r := Result{Name: fmt.Sprintf("Push to %s/%s", org, rpo)}
+
+		perms, err := gh.GetPermissions(org, rpo)
+		if gh.IsNotFound(err) && repo.ForkOwner != "" {
+			r.Status = Failed
+			r.Detail = "the fork doesn't exist"
+			r.Fix = fmt.Sprintf("Fork %s/%s on GitHub", repo.GetOrg(rpo), rpo)
+			return r
+		}
+		if err != nil {
+			r.Status = Failed
+			r.Detail = err.Error()
+			return r
+		}
+		if !perms.Push {
+			r.Status = Failed
+			r.Detail = "no push permission"
+			r.Fix = fmt.Sprintf("Ask a maintainer of %s/%s for write access, or push to your fork with --fork", org, rpo)
+		}
+		return r
+	}
+}
+
+// LabelExists checks the label the release PRs are tagged with exists on the repo
+func LabelExists(rpo, label string) Check {
+	return func() Result {
+		r := Result{Name: fmt.Sprintf("Label %q on %s/%s", label, repo.GetOrg(rpo), rpo)}
+		if _, err := gh.GetLabel(rpo, label); err != nil {
+			r.Status = Failed
+			r.Detail = err.Error()
+			if gh.IsNotFound(err) {
+				r.Detail = "missing"
+				r.Fix = "Create the release labels with `gbm-cli repos setup-labels`"
+			}
+		}
+		return r
+	}
+}
+
+// Tool checks the command is installed and reports its version
+func Tool(bin string, versionArgs ...string) Check {
+	return func() Result {
+		r := Result{Name: bin}
+		if _, err := exec.LookPath(bin); err != nil {
+			r.Status = Failed
+			r.Detail = "not found in PATH"
+			r.Fix = fmt.Sprintf("Install %s", bin)
+			return r
+		}
+		if len(versionArgs) == 0 {
+			versionArgs = []string{"--version"}
+		}
+		cmd := shell.NewExecCmd(bin, shell.CmdProps{})
+		if err := cmd.Exec(versionArgs...); err != nil {
+			r.Status = Warning
+			r.Detail = fmt.Sprintf("unable to get the version: %v", err)
+			return r
+		}
+		r.Detail = firstLine(cmd.LastResult().Stdout)
+		return r
+	}
+}
+
+func firstLine(s string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(s), "\n", 2)[0])
+}
+
+// Node checks npm resolves the node version the Gutenberg trunk asks for in its .nvmrc,
+// using the same node manager as the release commands
+func Node() Result {
+	r := Result{Name: "node"}
+	org := repo.GetOrg(repo.GutenbergRepo)
+	required, err := fetchNvmrc(org)
+	if err != nil {
+		r.Status = Warning
+		r.Detail = fmt.Sprintf("unable to get the node version Gutenberg requires: %v", err)
+		return r
+	}
+
+	// Resolve node like the commands do in the cloned repo, from a directory with the same .nvmrc
+	dir, err := os.MkdirTemp("", "gbm-doctor")
+	if err != nil {
+		r.Status = Warning
+		r.Detail = err.Error()
+		return r
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, ".nvmrc"), []byte(required), 0644); err != nil {
+		r.Status = Warning
+		r.Detail = err.Error()
+		return r
+	}
+
+	manager := shell.DetectNodeManager()
+	active, err := shell.NewNpmCmd(shell.CmdProps{Dir: dir}).NodeVersion()
+	if err != nil {
+		r.Status = Failed
+		r.Detail = fmt.Sprintf("unable to run npm with %s: %v", manager, err)
+		r.Fix = fmt.Sprintf("Install node %s with your node manager, e.g. `nvm install %s`", required, required)
+		return r
+	}
+	r.Detail = fmt.Sprintf("%s with %s, Gutenberg requires %s", active, manager, required)
+	if !shell.NodeSatisfies(active, required) {
+		r.Status = Failed
+		r.Fix = fmt.Sprintf("Install node %s with your node manager (nvm, fnm, volta, asdf or mise) or set %s to the manager to use", required, shell.NodeManagerEnv)
+	}
+	return r
+}
+
+func fetchNvmrc(org string) (string, error) {
+	resp, err := http.Get(fmt.Sprintf("https://raw.githubusercontent.com/%s/gutenberg/trunk/.nvmrc", org))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// DiskSpace checks there is room for the clones in dir, or its closest existing parent
+func DiskSpace(dir string, min uint64) Check {
+	return func() Result {
+		r := Result{Name: "Disk space"}
+		for {
+			if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+
+		free, err := freeSpace(dir)
+		if err != nil {
+			r.Status = Warning
+			r.Detail = fmt.Sprintf("unable to get the free space of %s: %v", dir, err)
+			return r
+		}
+		r.Detail = fmt.Sprintf("%s free in %s", formatBytes(free), dir)
+		if free < min {
+			r.Status = Failed
+			r.Detail += fmt.Sprintf(", the clones need about %s", formatBytes(min))
+			r.Fix = "Free up some space, clean up old workspaces with `gbm-cli workspace clean` or use --mirror-cache"
+		}
+		return r
+	}
+}
+
+func formatBytes(b uint64) string {
+	return fmt.Sprintf("%.1f GB", float64(b)/(1<<30))
+}
@@ -0,0 +1,55 @@
+package doctor
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+
+	t.Run("It reports failures", func(t *testing.T) {
+		results := []Result{{Status: Ok}, {Status: Warning}}
+		if HasFailures(results) {
+			t.Fatal("Expected no failures")
+		}
+		if !HasFailures(append(results, Result{Status: Failed})) {
+			t.Fatal("Expected a failure")
+		}
+	})
+
+	t.Run("It accepts the repo or public_repo scopes", func(t *testing.T) {
+		if !hasScope([]string{"read:org", "public_repo"}, "repo", "public_repo") {
+			t.Fatal("Expected public_repo to be enough")
+		}
+		if hasScope([]string{"read:org", "gist"}, "repo", "public_repo") {
+			t.Fatal("Expected the scopes to be missing")
+		}
+	})
+
+	t.Run("It checks the tools are installed with their version", func(t *testing.T) {
+		r := Tool("git")()
+		if r.Status != Ok || !strings.HasPrefix(r.Detail, "git version") {
+			t.Fatalf("got %+v", r)
+		}
+
+		r = Tool("gbm-doctor-missing-tool")()
+		if r.Status != Failed || r.Fix == "" {
+			t.Fatalf("got %+v", r)
+		}
+	})
+
+	t.Run("It checks the disk space of the closest existing directory", func(t *testing.T) {
+		dir := t.TempDir()
+		r := DiskSpace(filepath.Join(dir, "workspaces", "not-created-yet"), 1)()
+		if r.Status != Ok || !strings.HasSuffix(r.Detail, dir) {
+			t.Fatalf("got %+v", r)
+		}
+
+		r = DiskSpace(dir, math.MaxUint64)()
+		if r.Status != Failed || r.Fix == "" {
+			t.Fatalf("got %+v", r)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package gh
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Fake is a Transport that records requests instead of sending them.
+// Responses are replayed from the routes set with On, other requests get a 404.
+type Fake struct {
+	mu       sync.Mutex
+	requests []string
+	routes   []route
+}
+
+type route struct {
+	prefix *regexp.Regexp
+	status int
+	body   string
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// On replies to the requests starting with prefix, e.g. "GET repos/wordpress-mobile/gutenberg/pulls".
+// A * in the prefix matches anything, e.g. "GET search/issues?q=*repo:WordPress/gutenberg" as queries are unescaped.
+// The body is encoded as json unless it's a string. The latest matching route wins.
+func (f *Fake) On(prefix string, status int, body interface{}) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := body.(string)
+	if !ok {
+		data, _ := json.Marshal(body)
+		b = string(data)
+	}
+	re := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(prefix), `\*`, ".*"))
+	f.routes = append(f.routes, route{prefix: re, status: status, body: b})
+	return f
+}
+
+func (f *Fake) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	if req.URL.RawQuery != "" {
+		query, err := url.QueryUnescape(req.URL.RawQuery)
+		if err != nil {
+			query = req.URL.RawQuery
+		}
+		path += "?" + query
+	}
+	request := req.Method + " " + path
+	f.requests = append(f.requests, request)
+
+	status, body := http.StatusNotFound, `{"message": "Not Found"}`
+	for i := len(f.routes) - 1; i >= 0; i-- {
+		if f.routes[i].prefix.MatchString(request) {
+			status, body = f.routes[i].status, f.routes[i].body
+			break
+		}
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// Requests returns the recorded requests, e.g. "DELETE repos/wordpress-mobile/gutenberg/git/refs/tags/v1.0.0"
+func (f *Fake) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.requests...)
+}
+
+// Sent returns whether a request starting with prefix was sent
+func (f *Fake) Sent(prefix string) bool {
+	for _, r := range f.Requests() {
+		if strings.HasPrefix(r, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,14 +2,19 @@ package gh
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
 	"github.com/fatih/color"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
@@ -22,11 +27,14 @@ type Branch struct {
 	Commit struct {
 		Sha string
 	}
+	Protected  bool
 	StatusCode int
 }
 
 type Label struct {
-	Name string
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 type Repo struct {
@@ -44,6 +52,7 @@ type User struct {
 
 type PullRequest struct {
 	Number             int
+	NodeId             string `json:"node_id"`
 	Url                string `json:"html_url"`
 	ApiUrl             string `json:"url"`
 	Body               string
@@ -53,6 +62,7 @@ type PullRequest struct {
 	User               User
 	Draft              bool
 	Mergeable          bool
+	Merged             bool
 	Head               Repo
 	Base               Repo
 	RequestedReviewers []User `json:"requested_reviewers"`
@@ -129,6 +139,13 @@ type Ref struct {
 	}
 }
 
+// Comparison is the result of comparing two refs, see CompareRefs
+type Comparison struct {
+	Status   string
+	AheadBy  int `json:"ahead_by"`
+	BehindBy int `json:"behind_by"`
+}
+
 type Tag struct {
 	Sha string
 
@@ -305,7 +322,7 @@ func CreatePr(rpo string, pr *PullRequest) error {
 	}{
 		Title: pr.Title,
 		Body:  pr.Body,
-		Head:  pr.Head.Ref,
+		Head:  repo.HeadRef(pr.Head.Ref),
 		Base:  pr.Base.Ref,
 		Draft: pr.Draft,
 	}
@@ -324,7 +341,7 @@ func CreatePr(rpo string, pr *PullRequest) error {
 	pr.Labels = labels
 	if pr.Labels != nil {
 		if err := AddLabels(rpo, pr); err != nil {
-			console.Warn("Unable to add label '%s' to PR, are you sure it exists on the %s/%s repo?", err, org, rpo)
+			console.Warn("Unable to add the labels to the PR on %s/%s: %v. Create them with `gbm-cli repos setup-labels`", org, rpo, err)
 		}
 	}
 	return nil
@@ -358,6 +375,180 @@ func GetTag(rpo, tag string) (Tag, error) {
 	return t, nil
 }
 
+// GetCommitOrg returns the commit with the given sha in org/rpo.
+func GetCommitOrg(org, rpo, sha string) (Commit, error) {
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/commits/%s", org, rpo, sha)
+	c := Commit{}
+	if err := client.Get(endpoint, &c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// GetRefOrg returns the git ref (e.g. "tags/v1.0.0") in org/rpo.
+func GetRefOrg(org, rpo, ref string) (Ref, error) {
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/git/ref/%s", org, rpo, ref)
+	r := Ref{}
+	if err := client.Get(endpoint, &r); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// ListRefs returns the refs in rpo starting with prefix, e.g. "heads/release/".
+func ListRefs(rpo, prefix string) ([]Ref, error) {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/git/matching-refs/%s", org, rpo, prefix)
+	refs := []Ref{}
+	if err := client.Get(endpoint, &refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// CompareRefs returns how far head is ahead and behind base.
+func CompareRefs(rpo, base, head string) (Comparison, error) {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/compare/%s...%s", org, rpo, url.PathEscape(base), url.PathEscape(head))
+	c := Comparison{}
+	if err := client.Get(endpoint, &c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// MergeBranch merges head into the base branch on GitHub.
+// Returns false if there was nothing to merge.
+func MergeBranch(rpo, base, head, message string) (bool, error) {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/merges", org, rpo)
+
+	body := struct {
+		Base          string `json:"base"`
+		Head          string `json:"head"`
+		CommitMessage string `json:"commit_message"`
+	}{
+		Base:          base,
+		Head:          head,
+		CommitMessage: message,
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return false, err
+	}
+
+	resp, err := client.Request("POST", endpoint, &buf)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	// 204 means the base already contains the head
+	return resp.StatusCode != http.StatusNoContent, nil
+}
+
+// ListPrsByBase returns the open PRs targeting the base branch.
+func ListPrsByBase(rpo, base string) ([]PullRequest, error) {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/pulls?state=open&base=%s", org, rpo, url.QueryEscape(base))
+	prs := []PullRequest{}
+	if err := client.Get(endpoint, &prs); err != nil {
+		return nil, err
+	}
+	for i := range prs {
+		prs[i].Repo = rpo
+	}
+	return prs, nil
+}
+
+// UpdatePrBase changes the base branch of a PR.
+func UpdatePrBase(rpo string, pr *PullRequest, base string) error {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/pulls/%d", org, rpo, pr.Number)
+
+	body := struct {
+		Base string `json:"base"`
+	}{Base: base}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	return client.Patch(endpoint, &buf, pr)
+}
+
+// ClosePr closes a PR without merging it.
+func ClosePr(rpo string, pr *PullRequest) error {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/pulls/%d", org, rpo, pr.Number)
+
+	body := struct {
+		State string `json:"state"`
+	}{State: "closed"}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	return client.Patch(endpoint, &buf, pr)
+}
+
+// MarkReadyForReview takes a draft PR out of draft. The REST API can't do it so it goes through GraphQL.
+func MarkReadyForReview(pr *PullRequest) error {
+	if pr.NodeId == "" {
+		return fmt.Errorf("missing the node id of PR #%d", pr.Number)
+	}
+	client, err := api.NewGraphQLClient(api.ClientOptions{Transport: loggingTransport{base: Transport}})
+	if err != nil {
+		return err
+	}
+
+	query := `mutation($id: ID!) {
+		markPullRequestReadyForReview(input: {pullRequestId: $id}) {
+			pullRequest { isDraft }
+		}
+	}`
+	response := struct {
+		MarkPullRequestReadyForReview struct {
+			PullRequest struct {
+				IsDraft bool
+			}
+		}
+	}{}
+	if err := client.Do(query, map[string]interface{}{"id": pr.NodeId}, &response); err != nil {
+		return err
+	}
+	pr.Draft = response.MarkPullRequestReadyForReview.PullRequest.IsDraft
+	return nil
+}
+
+// DeleteRef deletes a ref, e.g. "heads/my-branch" or "tags/v1.0.0".
+func DeleteRef(rpo, ref string) error {
+	return DeleteRefOrg(repo.GetOrg(rpo), rpo, ref)
+}
+
+// DeleteRefOrg deletes the ref from the repo owned by org, e.g. a fork
+func DeleteRefOrg(org, rpo, ref string) error {
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/git/refs/%s", org, rpo, ref)
+	return client.Delete(endpoint, nil)
+}
+
+// IsNotFound reports whether err is a 404 response from the GitHub api.
+func IsNotFound(err error) bool {
+	var httpErr *api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
 // Adds labels to a PR
 func AddLabels(repo string, pr *PullRequest) error {
 
@@ -378,6 +569,240 @@ func AddLabels(repo string, pr *PullRequest) error {
 	return nil
 }
 
+// CurrentUser returns the user the GitHub token belongs to
+func CurrentUser() (User, error) {
+	user := User{}
+	client := getClient()
+	if err := client.Get("user", &user); err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+// Auth returns where the GitHub token comes from, e.g. GH_TOKEN or the gh config. Empty if there is no token.
+func Auth() string {
+	host, _ := auth.DefaultHost()
+	token, source := auth.TokenForHost(host)
+	if token == "" {
+		return ""
+	}
+	return source
+}
+
+// TokenScopes returns the user of the token and the OAuth scopes it was granted.
+// Fine grained tokens and GitHub App tokens don't report scopes, ok is false for them.
+func TokenScopes() (user User, scopes []string, ok bool, err error) {
+	client := getClient()
+	resp, err := client.Request(http.MethodGet, "user", nil)
+	if err != nil {
+		return user, nil, false, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return user, nil, false, err
+	}
+
+	header, ok := resp.Header["X-Oauth-Scopes"]
+	if !ok {
+		return user, nil, false, nil
+	}
+	for _, s := range strings.Split(strings.Join(header, ","), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return user, scopes, true, nil
+}
+
+// Permissions are what the token's user can do on a repo
+type Permissions struct {
+	Admin bool
+	Push  bool
+	Pull  bool
+}
+
+// GetPermissions returns the permissions of the token's user on org/rpo
+func GetPermissions(org, rpo string) (Permissions, error) {
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s", org, rpo)
+	response := struct {
+		Permissions Permissions
+	}{}
+	if err := client.Get(endpoint, &response); err != nil {
+		return Permissions{}, err
+	}
+	return response.Permissions, nil
+}
+
+// GetLabel returns the label of the repo with the name
+func GetLabel(rpo, name string) (Label, error) {
+	return GetLabelOrg(repo.GetOrg(rpo), rpo, name)
+}
+
+// GetLabelOrg returns the label of org/rpo
+func GetLabelOrg(org, rpo, name string) (Label, error) {
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/labels/%s", org, rpo, url.PathEscape(name))
+	label := Label{}
+	if err := client.Get(endpoint, &label); err != nil {
+		return label, err
+	}
+	return label, nil
+}
+
+// ListLabels returns all the labels of the repo
+func ListLabels(rpo string) ([]Label, error) {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+
+	labels := []Label{}
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("repos/%s/%s/labels?per_page=100&page=%d", org, rpo, page)
+		batch := []Label{}
+		if err := client.Get(endpoint, &batch); err != nil {
+			return nil, err
+		}
+		labels = append(labels, batch...)
+		if len(batch) < 100 {
+			return labels, nil
+		}
+	}
+}
+
+// CreateLabel adds the label to the repo
+func CreateLabel(rpo string, label Label) error {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/labels", org, rpo)
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(label); err != nil {
+		return err
+	}
+	return client.Post(endpoint, &buf, nil)
+}
+
+// UpdateLabel changes the label with the name, e.g. its color. Renames it if label has another name.
+func UpdateLabel(rpo, name string, label Label) error {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/labels/%s", org, rpo, url.PathEscape(name))
+
+	body := struct {
+		NewName     string `json:"new_name"`
+		Color       string `json:"color,omitempty"`
+		Description string `json:"description,omitempty"`
+	}{
+		NewName:     label.Name,
+		Color:       label.Color,
+		Description: label.Description,
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	return client.Patch(endpoint, &buf, nil)
+}
+
+// RequestReviewers requests reviews on the PR from the users and teams, team slugs without the org.
+func RequestReviewers(rpo string, pr *PullRequest, users, teams []string) error {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/pulls/%d/requested_reviewers", org, rpo, pr.Number)
+
+	body := struct {
+		Reviewers     []string `json:"reviewers"`
+		TeamReviewers []string `json:"team_reviewers"`
+	}{Reviewers: users, TeamReviewers: teams}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	return client.Post(endpoint, &buf, pr)
+}
+
+// AddAssignees assigns the users to the PR or issue
+func AddAssignees(rpo string, number int, users []string) error {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/issues/%d/assignees", org, rpo, number)
+
+	body := struct {
+		Assignees []string `json:"assignees"`
+	}{Assignees: users}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	return client.Post(endpoint, &buf, nil)
+}
+
+// GetPrFiles returns the paths of the files the PR changes
+func GetPrFiles(rpo string, number int) ([]string, error) {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+
+	files := []string{}
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("repos/%s/%s/pulls/%d/files?per_page=100&page=%d", org, rpo, number, page)
+		batch := []struct {
+			Filename string
+		}{}
+		if err := client.Get(endpoint, &batch); err != nil {
+			return nil, err
+		}
+		for _, f := range batch {
+			files = append(files, f.Filename)
+		}
+		if len(batch) < 100 {
+			return files, nil
+		}
+	}
+}
+
+// GetFileContent returns the content of the file at path on the ref of the repo
+func GetFileContent(rpo, ref, path string) (string, error) {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/contents/%s?ref=%s", org, rpo, path, url.QueryEscape(ref))
+
+	file := struct {
+		Content  string
+		Encoding string
+	}{}
+	if err := client.Get(endpoint, &file); err != nil {
+		return "", err
+	}
+	if file.Encoding != "base64" {
+		return file.Content, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CreateComment adds a comment to a PR or issue
+func CreateComment(rpo string, number int, body string) error {
+	org := repo.GetOrg(rpo)
+	client := getClient()
+	endpoint := fmt.Sprintf("repos/%s/%s/issues/%d/comments", org, rpo, number)
+
+	comment := struct {
+		Body string `json:"body"`
+	}{Body: body}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(comment); err != nil {
+		return err
+	}
+	return client.Post(endpoint, &buf, nil)
+}
+
 func GetStatusChecks(rpo, sha string) (Status, error) {
 	org := repo.GetOrg(rpo)
 
@@ -417,8 +842,28 @@ func GetStatus(rpo, sha string) (string, error) {
 	return status.State, nil
 }
 
+// Transport sends the GitHub API requests. Tests replace it with a Fake.
+var Transport http.RoundTripper = http.DefaultTransport
+
+// loggingTransport records every GitHub request in the debug log
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond).String()
+	if err != nil {
+		console.Record("github request", "method", req.Method, "url", req.URL.String(), "error", err.Error(), "duration", duration)
+		return res, err
+	}
+	console.Record("github request", "method", req.Method, "url", req.URL.String(), "status", res.StatusCode, "duration", duration)
+	return res, nil
+}
+
 func getClient() *api.RESTClient {
-	client, err := api.DefaultRESTClient()
+	client, err := api.NewRESTClient(api.ClientOptions{Transport: loggingTransport{base: Transport}})
 	if err != nil {
 		fmt.Printf("Error getting client: %v", err)
 		os.Exit(1)
@@ -0,0 +1,253 @@
+package gradle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Format is the flavor of gradle config a version was found in.
+type Format string
+
+const (
+	Groovy  Format = "groovy"
+	Kotlin  Format = "kotlin"
+	Catalog Format = "catalog"
+)
+
+// ConfigFiles are the files, relative to the project root, that are searched for a version.
+// Version catalogs come first since that is where WordPress-Android is heading.
+var ConfigFiles = []string{
+	filepath.Join("gradle", "libs.versions.toml"),
+	"build.gradle.kts",
+	"build.gradle",
+}
+
+// Match is a single version declaration found in a config file.
+type Match struct {
+	Path   string
+	Line   int
+	Key    string
+	Value  string
+	Format Format
+
+	// byte offsets of the value (without quotes) in the file
+	start int
+	end   int
+}
+
+func (m Match) String() string {
+	return fmt.Sprintf("%s:%d (%s = %q)", m.Path, m.Line, m.Key, m.Value)
+}
+
+// NotFoundError is returned when none of the keys are declared in any config file.
+type NotFoundError struct {
+	Keys     []string
+	Searched []string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("cannot find a version for %s in %s", strings.Join(e.Keys, ", "), strings.Join(e.Searched, ", "))
+}
+
+// AmbiguousError is returned when the version is declared more than once.
+type AmbiguousError struct {
+	Matches []Match
+}
+
+func (e *AmbiguousError) Error() string {
+	locs := []string{}
+	for _, m := range e.Matches {
+		locs = append(locs, m.String())
+	}
+	return fmt.Sprintf("found %d version declarations, expected one:\n  %s", len(e.Matches), strings.Join(locs, "\n  "))
+}
+
+// FindVersion returns the single declaration of any of the keys in the project at dir.
+func FindVersion(dir string, keys ...string) (Match, error) {
+	matches, searched, err := findAll(dir, keys)
+	if err != nil {
+		return Match{}, err
+	}
+	return single(matches, searched, keys)
+}
+
+// FindVersionIn returns the single declaration of any of the keys in configs, the content
+// of config files keyed by their path relative to the project root, e.g. fetched from GitHub.
+func FindVersionIn(configs map[string]string, keys ...string) (Match, error) {
+	matches := []Match{}
+	searched := []string{}
+	for _, f := range ConfigFiles {
+		config, ok := configs[f]
+		if !ok {
+			continue
+		}
+		searched = append(searched, f)
+		matches = append(matches, scan(f, formatOf(f), []byte(config), keys)...)
+	}
+
+	if len(searched) == 0 {
+		return Match{}, fmt.Errorf("no gradle config found (looked for %s)", strings.Join(ConfigFiles, ", "))
+	}
+	return single(matches, searched, keys)
+}
+
+func single(matches []Match, searched, keys []string) (Match, error) {
+	switch len(matches) {
+	case 0:
+		return Match{}, &NotFoundError{Keys: keys, Searched: searched}
+	case 1:
+		return matches[0], nil
+	default:
+		return Match{}, &AmbiguousError{Matches: matches}
+	}
+}
+
+// UpdateVersion replaces the value of the single declaration of any of the keys
+// in the project at dir. The quoting style of the declaration is preserved.
+// The returned match holds the value before the update.
+func UpdateVersion(dir, value string, keys ...string) (Match, error) {
+	m, err := FindVersion(dir, keys...)
+	if err != nil {
+		return m, err
+	}
+
+	config, err := os.ReadFile(m.Path)
+	if err != nil {
+		return m, err
+	}
+
+	updated := make([]byte, 0, len(config)+len(value))
+	updated = append(updated, config[:m.start]...)
+	updated = append(updated, value...)
+	updated = append(updated, config[m.end:]...)
+
+	if err := os.WriteFile(m.Path, updated, 0644); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+func findAll(dir string, keys []string) ([]Match, []string, error) {
+	matches := []Match{}
+	searched := []string{}
+
+	for _, f := range ConfigFiles {
+		path := filepath.Join(dir, f)
+		config, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		searched = append(searched, f)
+
+		format := formatOf(f)
+		matches = append(matches, scan(path, format, config, keys)...)
+	}
+
+	if len(searched) == 0 {
+		return nil, nil, fmt.Errorf("no gradle config found in %s (looked for %s)", dir, strings.Join(ConfigFiles, ", "))
+	}
+	return matches, searched, nil
+}
+
+func formatOf(file string) Format {
+	switch {
+	case strings.HasSuffix(file, ".toml"):
+		return Catalog
+	case strings.HasSuffix(file, ".kts"):
+		return Kotlin
+	default:
+		return Groovy
+	}
+}
+
+// Each pattern captures the key as group 1 and the quoted value as group 3.
+var (
+	// gutenbergMobileVersion = '...' inside an ext block, or ext.gutenbergMobileVersion = '...'
+	groovyRe = regexp.MustCompile(`^\s*(?:ext\.|project\.ext\.)?([\w.-]+)\s*=\s*(['"])(.*?)['"]`)
+
+	// extra["key"] = "...", extra.set("key", "..."), val key by extra("...") and val key = "..."
+	kotlinRes = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:project\.)?extra\[\s*"([\w.-]+)"\s*\]\s*=\s*(")(.*?)"`),
+		regexp.MustCompile(`^\s*(?:project\.)?extra\.set\(\s*"([\w.-]+)"\s*,\s*(")(.*?)"\s*\)`),
+		regexp.MustCompile(`^\s*val\s+(\w+)\s+by\s+extra\(\s*(")(.*?)"\s*\)`),
+		regexp.MustCompile(`^\s*val\s+(\w+)\s*(?::\s*String\s*)?=\s*(")(.*?)"`),
+	}
+
+	// key = "..." within the [versions] table
+	catalogRe      = regexp.MustCompile(`^\s*([\w.-]+)\s*=\s*(['"])(.*?)['"]`)
+	catalogTableRe = regexp.MustCompile(`^\s*\[\s*([\w.-]+)\s*\]`)
+)
+
+func scan(path string, format Format, config []byte, keys []string) []Match {
+	matches := []Match{}
+	table := ""
+	offset := 0
+
+	for i, line := range strings.SplitAfter(string(config), "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		if isComment(line) {
+			continue
+		}
+
+		var res []*regexp.Regexp
+		switch format {
+		case Catalog:
+			if t := catalogTableRe.FindStringSubmatch(line); t != nil {
+				table = t[1]
+				continue
+			}
+			if table != "versions" {
+				continue
+			}
+			res = []*regexp.Regexp{catalogRe}
+		case Kotlin:
+			res = kotlinRes
+		default:
+			res = []*regexp.Regexp{groovyRe}
+		}
+
+		for _, re := range res {
+			loc := re.FindStringSubmatchIndex(line)
+			if loc == nil {
+				continue
+			}
+			key := line[loc[2]:loc[3]]
+			if !contains(keys, key) {
+				continue
+			}
+			matches = append(matches, Match{
+				Path:   path,
+				Line:   i + 1,
+				Key:    key,
+				Value:  line[loc[6]:loc[7]],
+				Format: format,
+				start:  lineStart + loc[6],
+				end:    lineStart + loc[7],
+			})
+			break
+		}
+	}
+	return matches
+}
+
+func isComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "/*")
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
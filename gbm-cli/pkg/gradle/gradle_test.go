@@ -0,0 +1,179 @@
+package gradle
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var keys = []string{"gutenbergMobileVersion", "gutenberg-mobile"}
+
+func TestFindVersion(t *testing.T) {
+
+	t.Run("It finds the version in a groovy ext block", func(t *testing.T) {
+		m, err := FindVersion("testdata/groovy", keys...)
+		assertNoError(t, err)
+		assertEqual(t, m.Value, "v1.109.0")
+		assertEqual(t, m.Line, 8)
+		assertEqual(t, m.Format, Groovy)
+	})
+
+	t.Run("It finds the version in a kotlin build script", func(t *testing.T) {
+		m, err := FindVersion("testdata/kotlin", keys...)
+		assertNoError(t, err)
+		assertEqual(t, m.Value, "6123-abcdef")
+		assertEqual(t, m.Format, Kotlin)
+	})
+
+	t.Run("It finds the version in the versions table of a catalog", func(t *testing.T) {
+		m, err := FindVersion("testdata/catalog", keys...)
+		assertNoError(t, err)
+		assertEqual(t, m.Value, "v1.109.0")
+		assertEqual(t, m.Line, 4)
+		assertEqual(t, m.Format, Catalog)
+	})
+
+	t.Run("It reports every declaration when the version is ambiguous", func(t *testing.T) {
+		_, err := FindVersion("testdata/ambiguous", keys...)
+
+		var ambiguous *AmbiguousError
+		if !errors.As(err, &ambiguous) {
+			t.Fatalf("Expected an AmbiguousError, got %v", err)
+		}
+		assertEqual(t, len(ambiguous.Matches), 2)
+	})
+
+	t.Run("It reports the files searched when the version is missing", func(t *testing.T) {
+		_, err := FindVersion("testdata/missing", keys...)
+
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("Expected a NotFoundError, got %v", err)
+		}
+		assertEqual(t, notFound.Searched, []string{"build.gradle"})
+	})
+
+	t.Run("It returns an error if there is no gradle config", func(t *testing.T) {
+		_, err := FindVersion(t.TempDir(), keys...)
+		assertError(t, err)
+	})
+}
+
+func TestFindVersionIn(t *testing.T) {
+
+	t.Run("It finds the version in the fetched config files", func(t *testing.T) {
+		configs := map[string]string{
+			"build.gradle": read(t, "testdata/catalog/build.gradle"),
+			filepath.Join("gradle", "libs.versions.toml"): read(t, "testdata/catalog/gradle/libs.versions.toml"),
+		}
+		m, err := FindVersionIn(configs, keys...)
+		assertNoError(t, err)
+		assertEqual(t, m.Value, "v1.109.0")
+		assertEqual(t, m.Format, Catalog)
+	})
+
+	t.Run("It reports every declaration when the version is ambiguous", func(t *testing.T) {
+		configs := map[string]string{
+			"build.gradle": read(t, "testdata/ambiguous/build.gradle"),
+			filepath.Join("gradle", "libs.versions.toml"): read(t, "testdata/ambiguous/gradle/libs.versions.toml"),
+		}
+		_, err := FindVersionIn(configs, keys...)
+
+		var ambiguous *AmbiguousError
+		if !errors.As(err, &ambiguous) {
+			t.Fatalf("Expected an AmbiguousError, got %v", err)
+		}
+	})
+
+	t.Run("It returns an error if there is no gradle config", func(t *testing.T) {
+		_, err := FindVersionIn(map[string]string{}, keys...)
+		assertError(t, err)
+	})
+}
+
+func TestUpdateVersion(t *testing.T) {
+
+	t.Run("It only updates the version value", func(t *testing.T) {
+		dir := copyFixture(t, "testdata/groovy", "build.gradle")
+
+		prev, err := UpdateVersion(dir, "6123-abcdef", keys...)
+		assertNoError(t, err)
+		assertEqual(t, prev.Value, "v1.109.0")
+
+		got := read(t, filepath.Join(dir, "build.gradle"))
+		want := strings.Replace(read(t, "testdata/groovy/build.gradle"), "v1.109.0", "6123-abcdef", 1)
+		assertEqual(t, got, want)
+	})
+
+	t.Run("It preserves the quotes in a kotlin build script", func(t *testing.T) {
+		dir := copyFixture(t, "testdata/kotlin", "build.gradle.kts")
+
+		_, err := UpdateVersion(dir, "v1.110.0", keys...)
+		assertNoError(t, err)
+
+		m, err := FindVersion(dir, keys...)
+		assertNoError(t, err)
+		assertEqual(t, m.Value, "v1.110.0")
+	})
+
+	t.Run("It does not write anything when the version is ambiguous", func(t *testing.T) {
+		files := []string{"build.gradle", filepath.Join("gradle", "libs.versions.toml")}
+		dir := t.TempDir()
+		for _, f := range files {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, f), []byte(read(t, filepath.Join("testdata/ambiguous", f))), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		_, err := UpdateVersion(dir, "v1.110.0", keys...)
+		assertError(t, err)
+		for _, f := range files {
+			assertEqual(t, read(t, filepath.Join(dir, f)), read(t, filepath.Join("testdata/ambiguous", f)))
+		}
+	})
+}
+
+func copyFixture(t testing.TB, from, file string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(read(t, filepath.Join(from, file))), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func read(t testing.TB, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func assertEqual(t testing.TB, got, want interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func assertError(t testing.TB, err error) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("Expected an error, got nil")
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,6 @@
+plugins {
+    id("com.android.application") version "8.1.0" apply false
+}
+
+extra["gutenbergMobileVersion"] = "6123-abcdef"
+extra["wordPressAztecVersion"] = "v1.8.0"
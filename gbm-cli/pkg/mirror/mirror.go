@@ -0,0 +1,119 @@
+package mirror
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+// Enabled turns on the mirror cache. Set it with the --mirror-cache flag or GBM_MIRROR_CACHE.
+var Enabled = os.Getenv("GBM_MIRROR_CACHE") != ""
+
+// Set GBM_MIRROR_DIR to keep the mirrors somewhere else than the user cache directory
+const DirEnv = "GBM_MIRROR_DIR"
+
+// Dir returns the directory the mirrors are kept in, e.g. ~/.cache/gbm-cli/mirrors
+func Dir() (string, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "gbm-cli", "mirrors"), nil
+}
+
+// Path returns where the mirror of the repo is kept, e.g. ~/.cache/gbm-cli/mirrors/WordPress/gutenberg.git
+func Path(rpo string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, repo.GetOrg(rpo), rpo+".git"), nil
+}
+
+// Update creates the mirror of the repo, or fetches what changed since the last run.
+// Only branches and tags are mirrored, GitHub's pull request refs would make Gutenberg far bigger.
+func Update(rpo string, sp shell.CmdProps) (string, error) {
+	path, err := Path(rpo)
+	if err != nil {
+		return "", err
+	}
+
+	git := shell.NewGitCmd(withDir(sp, path))
+	run := shell.NewExecCmd("git", withDir(sp, path))
+
+	if _, err := os.Stat(filepath.Join(path, "HEAD")); err != nil {
+		console.Info("Creating the %s mirror in %s, the first run takes a while", rpo, path)
+		if err := os.MkdirAll(path, os.ModePerm); err != nil {
+			return "", err
+		}
+
+		// The mirror is public, so the url must not contain the token
+		url := fmt.Sprintf("https://github.com/%s/%s", repo.GetOrg(rpo), rpo)
+		setup := [][]string{
+			{"init", "--bare", "--quiet"},
+			{"remote", "add", "origin", url},
+			{"config", "remote.origin.fetch", "+refs/heads/*:refs/heads/*"},
+			{"config", "--add", "remote.origin.fetch", "+refs/tags/*:refs/tags/*"},
+			// The clones borrow the mirror's objects, so they must never be pruned
+			{"config", "gc.auto", "0"},
+		}
+		for _, args := range setup {
+			if err := run.Exec(args...); err != nil {
+				os.RemoveAll(path)
+				return "", fmt.Errorf("error creating the %s mirror: %v", rpo, err)
+			}
+		}
+	} else {
+		console.Info("Updating the %s mirror", rpo)
+	}
+
+	if err := git.FetchRemote("origin", "--prune"); err != nil {
+		return "", fmt.Errorf("error updating the %s mirror: %v", rpo, err)
+	}
+	return path, nil
+}
+
+// Clone clones the repo at branch into sp.Dir.
+// Without the cache it's a shallow clone, otherwise a full clone borrowing the objects of the mirror.
+func Clone(rpo, branch string, sp shell.CmdProps, recursive bool) error {
+	git := shell.NewGitCmd(sp)
+	url := repo.GetRepoHttpsPath(rpo)
+
+	if !Enabled {
+		args := []string{url, "--branch", branch, "--depth=1"}
+		if recursive {
+			args = append(args, "--recursive")
+		}
+		return git.Clone(append(args, ".")...)
+	}
+
+	path, err := Update(rpo, sp)
+	if err != nil {
+		return err
+	}
+	if err := git.Clone("--reference", path, "--branch", branch, url, "."); err != nil {
+		return err
+	}
+	if !recursive {
+		return nil
+	}
+
+	// Submodules can't borrow from the superproject's mirror, only Gutenberg is worth caching
+	gbPath, err := Update(repo.GutenbergRepo, sp)
+	if err != nil {
+		return err
+	}
+	return git.Submodule("update", "--init", "--recursive", "--reference", gbPath)
+}
+
+func withDir(sp shell.CmdProps, dir string) shell.CmdProps {
+	sp.Dir = dir
+	return sp
+}
@@ -0,0 +1,87 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+func TestClone(t *testing.T) {
+	setup := func(t *testing.T, enabled bool) (*shell.Fake, shell.CmdProps) {
+		t.Helper()
+		t.Setenv(DirEnv, t.TempDir())
+		prev := Enabled
+		Enabled = enabled
+		t.Cleanup(func() { Enabled = prev })
+
+		fake := shell.NewFake()
+		return fake, shell.CmdProps{Dir: t.TempDir(), Backend: fake}
+	}
+
+	t.Run("It makes a shallow clone without the cache", func(t *testing.T) {
+		fake, sp := setup(t, false)
+
+		assertNoError(t, Clone("gutenberg", "trunk", sp, false))
+
+		want := []string{"git clone " + repo.GetRepoHttpsPath("gutenberg") + " --branch trunk --depth=1 ."}
+		assertEqual(t, fake.Commands(), want)
+	})
+
+	t.Run("It creates the mirror and clones from it", func(t *testing.T) {
+		fake, sp := setup(t, true)
+
+		assertNoError(t, Clone("gutenberg", "trunk", sp, false))
+
+		path, _ := Path("gutenberg")
+		want := []string{
+			"git init --bare --quiet",
+			"git remote add origin https://github.com/" + repo.GetOrg("gutenberg") + "/gutenberg",
+			"git config remote.origin.fetch +refs/heads/*:refs/heads/*",
+			"git config --add remote.origin.fetch +refs/tags/*:refs/tags/*",
+			"git config gc.auto 0",
+			"git fetch origin --prune",
+			"git clone --reference " + path + " --branch trunk " + repo.GetRepoHttpsPath("gutenberg") + " .",
+		}
+		assertEqual(t, fake.Commands(), want)
+		assertEqual(t, fake.Calls()[0].Dir, path)
+	})
+
+	t.Run("It only fetches an existing mirror", func(t *testing.T) {
+		fake, sp := setup(t, true)
+		path, _ := Path("gutenberg-mobile")
+		assertNoError(t, os.MkdirAll(path, os.ModePerm))
+		assertNoError(t, os.WriteFile(filepath.Join(path, "HEAD"), []byte("ref: refs/heads/trunk\n"), 0644))
+
+		gbPath, _ := Path("gutenberg")
+		assertNoError(t, os.MkdirAll(gbPath, os.ModePerm))
+		assertNoError(t, os.WriteFile(filepath.Join(gbPath, "HEAD"), []byte("ref: refs/heads/trunk\n"), 0644))
+
+		assertNoError(t, Clone("gutenberg-mobile", "trunk", sp, true))
+
+		cmds := strings.Join(fake.Commands(), "\n")
+		if strings.Contains(cmds, "git init") {
+			t.Fatalf("Expected the mirror to be reused, got:\n%s", cmds)
+		}
+		last := fake.Commands()[len(fake.Commands())-1]
+		assertEqual(t, last, "git submodule update --init --recursive --reference "+gbPath)
+	})
+}
+
+func assertEqual(t testing.TB, got, want interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/semver"
+)
+
+// AfterBranch is a `gutenberg/after_<version>` branch on a host app repo.
+// Work that depends on the release lands there until the integration PR is merged.
+type AfterBranch struct {
+	Repo     string
+	Name     string
+	Version  semver.SemVer
+	Base     string
+	AheadBy  int
+	BehindBy int
+}
+
+func AfterBranchName(version string) string {
+	return fmt.Sprintf(IntegrateAfterBranchName, version)
+}
+
+// FindAfterBranches returns the after branches on the repo with how far they are ahead and behind base.
+func FindAfterBranches(rpo, base string) ([]AfterBranch, error) {
+	prefix := strings.TrimSuffix(IntegrateAfterBranchName, "%s")
+	refs, err := gh.ListRefs(rpo, "heads/"+prefix)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the after branches on %s: %v", rpo, err)
+	}
+
+	branches := []AfterBranch{}
+	for _, r := range refs {
+		name := strings.TrimPrefix(r.Ref, "refs/heads/")
+		v, err := semver.NewSemVer(strings.TrimPrefix(name, prefix))
+		if err != nil {
+			// Not one of ours
+			continue
+		}
+
+		ab := AfterBranch{Repo: rpo, Name: name, Version: v, Base: base}
+		cmp, err := gh.CompareRefs(rpo, base, name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compare %s with %s on %s: %v", name, base, rpo, err)
+		}
+		ab.AheadBy, ab.BehindBy = cmp.AheadBy, cmp.BehindBy
+		branches = append(branches, ab)
+	}
+	return branches, nil
+}
+
+// StaleAfterBranches returns the after branches of releases prior to version
+func StaleAfterBranches(branches []AfterBranch, version semver.SemVer) []AfterBranch {
+	stale := []AfterBranch{}
+	for _, b := range branches {
+		if b.Version.Compare(version) < 0 {
+			stale = append(stale, b)
+		}
+	}
+	return stale
+}
+
+// MergeBaseIntoAfterBranch brings the after branch up to date with its base.
+// Returns false if the after branch already contained the base.
+func MergeBaseIntoAfterBranch(ab AfterBranch) (bool, error) {
+	message := fmt.Sprintf("Merge %s into %s", ab.Base, ab.Name)
+	return gh.MergeBranch(ab.Repo, ab.Name, ab.Base, message)
+}
+
+// RetargetAfterBranchPrs points the open PRs based on the after branch to its base.
+// This should only happen once the integration PR is merged, otherwise the PRs would
+// land before the release they depend on.
+func RetargetAfterBranchPrs(ab AfterBranch) ([]gh.PullRequest, error) {
+	prs, err := gh.ListPrsByBase(ab.Repo, ab.Name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the PRs based on %s: %v", ab.Name, err)
+	}
+
+	retargeted := []gh.PullRequest{}
+	for _, pr := range prs {
+		if err := gh.UpdatePrBase(ab.Repo, &pr, ab.Base); err != nil {
+			return retargeted, fmt.Errorf("unable to retarget PR #%d: %v", pr.Number, err)
+		}
+		retargeted = append(retargeted, pr)
+	}
+	return retargeted, nil
+}
+
+// DeleteAfterBranch deletes the after branch from the remote.
+// It refuses while PRs are based on it, since deleting it would close them.
+func DeleteAfterBranch(ab AfterBranch) error {
+	prs, err := gh.ListPrsByBase(ab.Repo, ab.Name)
+	if err != nil {
+		return fmt.Errorf("unable to list the PRs based on %s: %v", ab.Name, err)
+	}
+	if len(prs) > 0 {
+		return fmt.Errorf("%d open PRs are based on %s, retarget them first", len(prs), ab.Name)
+	}
+	return gh.DeleteRef(ab.Repo, "heads/"+ab.Name)
+}
+
+// IntegrationPrMerged checks if the integration PR for the version was merged on the repo
+func IntegrationPrMerged(rpo, version string) (bool, error) {
+	filter := gh.BuildRepoFilter(rpo, "is:pr", "is:merged", "label:"+IntegratePrLabel, fmt.Sprintf(IntegratePrTitle+" in:title", version))
+	pr, err := gh.SearchPr(filter)
+	if err != nil {
+		return false, err
+	}
+	return pr.Merged, nil
+}
@@ -0,0 +1,52 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+func TestDeleteAfterBranch(t *testing.T) {
+	ios := "repos/" + repo.GetOrg("WordPress-iOS") + "/WordPress-iOS"
+	ab := AfterBranch{Repo: "WordPress-iOS", Name: "gutenberg/after_1.108.0", Base: "trunk"}
+
+	t.Run("It deletes an after branch without PRs based on it", func(t *testing.T) {
+		api := fakeGitHub(t).
+			On("GET "+ios+"/pulls", 200, []gh.PullRequest{}).
+			On("DELETE "+ios+"/git/refs/heads/gutenberg/after_1.108.0", 204, "")
+
+		if err := DeleteAfterBranch(ab); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !api.Sent("DELETE " + ios) {
+			t.Fatalf("Expected the branch to be deleted, got %v", api.Requests())
+		}
+	})
+
+	t.Run("It refuses to delete an after branch with PRs based on it", func(t *testing.T) {
+		api := fakeGitHub(t).On("GET "+ios+"/pulls", 200, []gh.PullRequest{{Number: 12}})
+
+		if err := DeleteAfterBranch(ab); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		if api.Sent("DELETE " + ios) {
+			t.Fatalf("Expected the branch to be kept, got %v", api.Requests())
+		}
+	})
+}
+
+func TestIntegrationPrMerged(t *testing.T) {
+
+	t.Run("It only searches the merged integration PRs", func(t *testing.T) {
+		api := fakeGitHub(t).On("GET search/issues", 200, gh.SearchResult{})
+
+		merged, err := IntegrationPrMerged("WordPress-iOS", "1.109.0")
+		if err != nil || merged {
+			t.Fatalf("Expected no merged PR, got %v %v", merged, err)
+		}
+		if !api.Sent("GET search/issues?q=is:pr is:merged") {
+			t.Fatalf("Expected the search to filter merged PRs, got %v", api.Requests())
+		}
+	})
+}
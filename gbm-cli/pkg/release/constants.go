@@ -1,10 +1,16 @@
 package release
 
+import "time"
+
 const GbReleasePrLabel = "Mobile App - i.e. Android or iOS"
 
+const GbBuildToolingLabel = "[Type] Build Tooling"
+
 const GbmReleasePrLabel = "release-process"
 
 const IntegrateBranchName = "gutenberg/integrate_release_%s"
 const IntegrateAfterBranchName = "gutenberg/after_%s"
 const IntegratePrTitle = "Integrate gutenberg-mobile release v%s"
 const IntegratePrLabel = "Gutenberg"
+
+const PodInstallTimeout = 30 * time.Minute
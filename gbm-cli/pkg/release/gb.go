@@ -6,8 +6,10 @@ import (
 
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/mirror"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/render"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/report"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
 )
 
@@ -18,19 +20,22 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 
 	isPatch := build.Version.IsPatchRelease()
 
-	shellProps := shell.CmdProps{Dir: dir, Verbose: true}
+	shellProps := build.shellProps(dir)
 	git := shell.NewGitCmd(shellProps)
 	npm := shell.NewNpmCmd(shellProps)
 
 	org := repo.GetOrg("gutenberg")
 	branch := "rnmobile/release_" + version
 
-	exists, _ := gh.SearchBranch("gutenberg", branch)
+	exists, err := BranchExists("gutenberg", branch)
+	if err != nil {
+		return pr, fmt.Errorf("error checking the branch %s: %v", branch, err)
+	}
 
-	if (exists != gh.Branch{}) {
-		console.Warn("Branch %s already exists", branch)
+	if exists {
+		console.Warn("Branch %s already exists on %s/gutenberg", branch, pushOrg("gutenberg"))
 
-		cont := console.Confirm("Do you wish to continue?")
+		cont := console.Confirm("continue_existing_gb_branch", "Do you wish to continue?")
 
 		if !cont {
 			console.Info("Bye 👋")
@@ -38,19 +43,10 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 		}
 		return pr, fmt.Errorf("existing branch not implemented yet")
 	} else {
-		console.Info("Cloning Gutenberg to %s", dir)
-
-		// Let's clone into the current directory so that the git client can find the .git directory
-		err := git.Clone(repo.GetRepoHttpsPath("gutenberg"), "--branch", build.Base.Ref, "--depth=1", ".")
-		if err != nil {
-			return pr, fmt.Errorf("error cloning the Gutenberg repository: %v", err)
-		}
-
-		console.Info("Checking out branch %s", branch)
-		err = git.Switch("-c", branch)
-		if err != nil {
-			return pr, fmt.Errorf("error checking out the branch: %v", err)
+		if err := checkoutBranch(build, "gutenberg", branch, false, git); err != nil {
+			return pr, fmt.Errorf("error setting up the Gutenberg repository: %v", err)
 		}
+		RecordBase("gutenberg", build.Base.Ref, git)
 	}
 
 	if isPatch {
@@ -58,7 +54,12 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 		// for testing this is useful to allow and to skip.
 		if len(build.Prs) != 0 {
 			console.Info("Cherry picking PRs")
-			err := git.Fetch("trunk", build.Depth)
+			fetch := []string{"trunk"}
+			// Local checkouts and clones from the mirror cache already have the full history
+			if build.Depth != "" && !build.Local && !mirror.Enabled {
+				fetch = append(fetch, build.Depth)
+			}
+			err := git.Fetch(fetch...)
 			if err != nil {
 				return pr, fmt.Errorf("error fetching the Gutenberg repository: %v", err)
 			}
@@ -68,6 +69,7 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 					return pr, fmt.Errorf("error cherry picking PR %d: no merge commit", pr.Number)
 				}
 				console.Info("Cherry picking PR %d via commit %s", pr.Number, pr.MergeCommit)
+				report.RecordCherryPick("gutenberg", pr.Number, pr.MergeCommit)
 
 				if err := git.CherryPick(pr.MergeCommit); err != nil {
 
@@ -89,7 +91,7 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 						console.Warn("There was an issue opening the conflicting files in your editor: %v", err)
 					}
 
-					fixed := console.Confirm("Continue after resolving the conflict?")
+					fixed := console.Confirm("continue_after_conflict", "Continue after resolving the conflict?")
 
 					if fixed {
 						err := git.CherryPick("--continue")
@@ -140,7 +142,7 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 			console.Warn("There was an issue opening the CHANGELOG in your editor: %v", err)
 		}
 
-		if cont := console.Confirm("Do you wish to continue after updating the CHANGELOG?"); !cont {
+		if cont := console.Confirm("continue_after_changelog", "Do you wish to continue after updating the CHANGELOG?"); !cont {
 			return pr, fmt.Errorf("exiting before creating PR, Stopping at CHANGELOG update")
 		}
 	}
@@ -151,6 +153,10 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 
 	console.Info("Setting up Gutenberg node environment")
 
+	if err := npm.VerifyNode(); err != nil {
+		return pr, err
+	}
+
 	if err := npm.Install(); err != nil {
 		return pr, fmt.Errorf("error running npm install: %v", err)
 	}
@@ -160,7 +166,7 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 	// Run bundle install directly since the preios script sometimes fails
 	editorIosPath := filepath.Join(dir, "packages", "react-native-editor", "ios")
 
-	iosShellProps := shell.CmdProps{Dir: editorIosPath, Verbose: true}
+	iosShellProps := build.shellProps(editorIosPath)
 	bundle := shell.NewBundlerCmd(iosShellProps)
 	if err := bundle.Install(); err != nil {
 		return pr, fmt.Errorf("error running bundle install: %v", err)
@@ -188,10 +194,10 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 
 	pr.Labels = []gh.Label{
 		{
-			Name: "Mobile App - i.e. Android or iOS",
+			Name: GbReleasePrLabel,
 		},
 		{
-			Name: "[Type] Build Tooling",
+			Name: GbBuildToolingLabel,
 		},
 	}
 
@@ -200,15 +206,16 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 	var prompt string
 
 	prompt = fmt.Sprintf("\nReady to create the PR on %s/gutenberg?", org)
-	shouldCreatePr := console.Confirm(prompt)
+	shouldCreatePr := console.Confirm("create_gb_pr", prompt)
 
 	if !shouldCreatePr {
 		return pr, fmt.Errorf("exiting before creating PR")
 	}
 
-	if err := git.Push(); err != nil {
+	if err := PushBranch("gutenberg", git); err != nil {
 		return pr, fmt.Errorf("error pushing the PR: %v", err)
 	}
+	RecordHead("gutenberg", git)
 
 	if err := gh.CreatePr("gutenberg", &pr); err != nil {
 		return pr, fmt.Errorf("error creating the PR: %v", err)
@@ -217,13 +224,24 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 	if pr.Number == 0 {
 		return pr, fmt.Errorf("pr was not created successfully")
 	}
+	report.RecordPr("gutenberg", pr)
+	AssignAndRequestReviews("gutenberg", &pr)
 
 	// Only tag if we are prompting to tag
 	var shouldTag bool
 
+	if build.PromptToTag && repo.ForkOwner != "" {
+		// The tag belongs upstream where the PR will be merged, not on the fork
+		console.Warn("Skipping tag creation when using a fork, ask a maintainer of %s/gutenberg to tag the release", org)
+		report.RecordSkip("gutenberg", "tag", "--fork")
+		build.PromptToTag = false
+	} else if !build.PromptToTag {
+		report.RecordSkip("gutenberg", "tag", "--no-tag")
+	}
+
 	if build.PromptToTag {
 		prompt = fmt.Sprintf("\nDo you want to create the release tag on %s/gutenberg?", org)
-		shouldTag = console.Confirm(prompt)
+		shouldTag = console.Confirm("create_gb_tag", prompt)
 	} else {
 		shouldTag = false
 	}
@@ -234,6 +252,9 @@ func CreateGbPR(build Build) (gh.PullRequest, error) {
 			console.Warn("Error tagging the release: %v", err)
 		}
 	} else {
+		if build.PromptToTag {
+			report.RecordSkip("gutenberg", "tag", "declined")
+		}
 		console.Warn("Skipping tag creation")
 	}
 
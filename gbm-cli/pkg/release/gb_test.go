@@ -0,0 +1,126 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/render"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/semver"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+func TestCreateGbPR(t *testing.T) {
+
+	t.Run("It prepares the release branch and creates the PR", func(t *testing.T) {
+		t.Setenv("CI", "true")
+		console.Yes = true
+		t.Cleanup(func() { console.Yes = false })
+		useTemplates(t)
+
+		dir := t.TempDir()
+		changelog := filepath.Join(dir, "packages", "react-native-editor", "CHANGELOG.md")
+		if err := os.MkdirAll(filepath.Dir(changelog), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(changelog, []byte("## Unreleased\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		version, _ := semver.NewSemVer("1.109.0")
+		fake := shell.NewFake().
+			On("git remote get-url origin", "https://github.com/"+repo.GetOrg("gutenberg")+"/gutenberg.git\n", 0).
+			On("git rev-parse", "1234567890abcdef\n", 0).
+			On("git status", "# branch.head rnmobile/release_1.109.0\x00", 0).
+			On("git ls-remote", "", 2)
+		build := Build{Dir: dir, Version: version, Local: true, Base: gh.Repo{Ref: "trunk"}, Shell: fake}
+		api := fakeGitHub(t).
+			On("POST repos/"+repo.GetOrg("gutenberg")+"/gutenberg/pulls", 201, gh.PullRequest{Number: 42})
+
+		pr, err := CreateGbPR(build)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Number != 42 {
+			t.Fatalf("Expected the created PR, got %v", pr)
+		}
+
+		data, err := os.ReadFile(changelog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, string(data), "## Unreleased\n\n## 1.109.0\n")
+
+		want := []string{
+			"git switch -c rnmobile/release_1.109.0 1234567890abcdef",
+			"npm install",
+			"bundle install",
+			"npm run preios",
+			"git push --set-upstream origin HEAD:refs/heads/rnmobile/release_1.109.0",
+		}
+		if got := filterCommands(fake.Commands(), want); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", fake.Commands(), want)
+		}
+		if !api.Sent("POST repos/" + repo.GetOrg("gutenberg") + "/gutenberg/pulls") {
+			t.Fatalf("Expected the PR to be created, got %v", api.Requests())
+		}
+	})
+
+	t.Run("It checks the release branch on the fork", func(t *testing.T) {
+		t.Cleanup(repo.InitOrgs)
+		t.Setenv("GBM_FORK", "octocat")
+		repo.InitOrgs()
+		t.Setenv("CI", "true")
+
+		version, _ := semver.NewSemVer("1.109.0")
+		fake := shell.NewFake()
+		build := Build{Dir: t.TempDir(), Version: version, Local: true, Base: gh.Repo{Ref: "trunk"}, Shell: fake}
+		api := fakeGitHub(t).
+			On("GET repos/octocat/gutenberg/git/ref/heads/rnmobile/release_1.109.0", 200, gh.Ref{Ref: "refs/heads/rnmobile/release_1.109.0"})
+
+		if _, err := CreateGbPR(build); err == nil {
+			t.Fatal("Expected an error for the existing branch")
+		}
+		if len(fake.Commands()) != 0 {
+			t.Fatalf("Expected nothing to run, got %v", fake.Commands())
+		}
+		if api.Sent("GET repos/" + repo.GetOrg("gutenberg") + "/gutenberg/") {
+			t.Fatalf("Expected the upstream branch not to be checked, got %v", api.Requests())
+		}
+	})
+
+	t.Run("It fails when the release branch can't be checked", func(t *testing.T) {
+		version, _ := semver.NewSemVer("1.109.0")
+		build := Build{Dir: t.TempDir(), Version: version, Local: true, Base: gh.Repo{Ref: "trunk"}, Shell: shell.NewFake()}
+		fakeGitHub(t).On("GET repos/"+repo.GetOrg("gutenberg")+"/gutenberg/git/ref/heads/rnmobile/release_1.109.0", 500, "")
+
+		if _, err := CreateGbPR(build); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+// Renders the templates of the repo
+func useTemplates(t *testing.T) {
+	t.Helper()
+	prev := render.TemplateFS
+	render.TemplateFS = os.DirFS(filepath.Join("..", ".."))
+	t.Cleanup(func() { render.TemplateFS = prev })
+}
+
+// Returns the commands that are in want, in the order they ran
+func filterCommands(cmds, want []string) []string {
+	got := []string{}
+	for _, c := range cmds {
+		for _, w := range want {
+			if c == w {
+				got = append(got, c)
+			}
+		}
+	}
+	return got
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/render"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/report"
 )
 
 func CreateGbmPR(build Build) (gh.PullRequest, error) {
@@ -21,7 +22,7 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 	version := build.Version.String()
 	dir := build.Dir
 
-	sp := shell.CmdProps{Dir: dir, Verbose: true}
+	sp := build.shellProps(dir)
 	git := shell.NewGitCmd(sp)
 
 	// Set Gutenberg Mobile repository and org
@@ -34,23 +35,20 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 	// Return if it does
 	// Otherwise, clone the repo and checkout the branch
 	console.Info("Checking if branch %s exists", branch)
-	exists, _ := gh.SearchBranch("gutenberg-mobile", branch)
+	exists, err := BranchExists("gutenberg-mobile", branch)
+	if err != nil {
+		return pr, fmt.Errorf("error checking the branch %s: %v", branch, err)
+	}
 
-	if (exists != gh.Branch{}) {
+	if exists {
 		console.Info("Branch %s already exists", branch)
+		report.RecordSkip("gutenberg-mobile", "release branch", "branch already exists")
 		return pr, nil
 	} else {
-		console.Info("Cloning Gutenberg Mobile to %s", dir)
-		err := git.Clone(repo.GetRepoHttpsPath("gutenberg-mobile"), "--branch", build.Base.Ref, "--depth=1", "--recursive", ".")
-		if err != nil {
-			return pr, fmt.Errorf("error cloning the Gutenberg Mobile repository: %v", err)
-		}
-
-		console.Info("Setting up the branch %s", branch)
-		err = git.Switch("-c", branch)
-		if err != nil {
-			return pr, fmt.Errorf("error switching to the branch: %v", err)
+		if err := checkoutBranch(build, "gutenberg-mobile", branch, true, git); err != nil {
+			return pr, fmt.Errorf("error setting up the Gutenberg Mobile repository: %v", err)
 		}
+		RecordBase("gutenberg-mobile", build.Base.Ref, git)
 	}
 
 	// Update the Gutenberg submodule
@@ -58,10 +56,13 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 	if org != repo.WpMobileOrg {
 		console.Warn("You are not using the %s org. Check the .gitmodules file to make sure the gutenberg submodule is pointing to %s/gutenberg.", repo.WpMobileOrg, org)
 	}
-	if exists, _ := gh.SearchBranch("gutenberg", gbBranch); (exists == gh.Branch{}) {
-		return pr, fmt.Errorf("the Gutenberg branch %s does not exist on %s/gutenberg-mobile", gbBranch, org)
+	// With a fork the Gutenberg branch was pushed to the fork, which is where the submodule is updated from
+	if exists, err := BranchExists("gutenberg", gbBranch); err != nil {
+		return pr, fmt.Errorf("error checking the Gutenberg branch %s: %v", gbBranch, err)
+	} else if !exists {
+		return pr, fmt.Errorf("the Gutenberg branch %s does not exist on %s/gutenberg", gbBranch, pushOrg("gutenberg"))
 	}
-	if err := updateGbSubmodule(gbBranch, dir, git); err != nil {
+	if err := updateGbSubmodule(build, gbBranch, git); err != nil {
 		return pr, fmt.Errorf("error updating the Gutenberg submodule: %v", err)
 	}
 
@@ -70,7 +71,15 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 	npm := shell.NewNpmCmd(sp)
 
 	// Run npm ci and npm run bundle
-	if err := npm.Ci(); err != nil {
+	// Local checkouts keep their node_modules, npm install only updates what changed
+	if build.Local {
+		if err := npm.VerifyNode(); err != nil {
+			return pr, err
+		}
+		if err := npm.Install(); err != nil {
+			return pr, fmt.Errorf("error running npm install: %v", err)
+		}
+	} else if err := npm.Ci(); err != nil {
 		return pr, fmt.Errorf("error running npm ci: %v", err)
 	}
 
@@ -93,7 +102,7 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 		return pr, fmt.Errorf("error committing the bundle update: %v", err)
 	}
 
-	if err := updateXcFramework(version, dir, git); err != nil {
+	if err := updateXcFramework(build, git); err != nil {
 		return pr, fmt.Errorf("error updating the XCFramework builders project: %v", err)
 	}
 
@@ -111,7 +120,7 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 			console.Warn("There was an issue opening RELEASE-NOTES.txt in your editor: %v", err)
 		}
 
-		if cont := console.Confirm("Do you wish to continue after updating RELEASE-NOTES.txt?"); !cont {
+		if cont := console.Confirm("continue_after_release_notes", "Do you wish to continue after updating RELEASE-NOTES.txt?"); !cont {
 			return pr, fmt.Errorf("exiting before creating PR, Stopping at RELEASE-NOTES.txt update")
 		}
 	}
@@ -134,7 +143,7 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 
 	// Add PR labels
 	pr.Labels = []gh.Label{{
-		Name: "release-process",
+		Name: GbmReleasePrLabel,
 	}}
 
 	// Display PR preview
@@ -142,7 +151,7 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 
 	// Add prompt to confirm PR creation
 	prompt := fmt.Sprintf("\nReady to create the PR on %s/gutenberg-mobile?", org)
-	cont := console.Confirm(prompt)
+	cont := console.Confirm("create_gbm_pr", prompt)
 
 	if !cont {
 		console.Info("Bye 👋")
@@ -150,9 +159,10 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 	}
 
 	// Push the branch
-	if err := git.Push(); err != nil {
+	if err := PushBranch("gutenberg-mobile", git); err != nil {
 		return pr, fmt.Errorf("error pushing the branch: %v", err)
 	}
+	RecordHead("gutenberg-mobile", git)
 
 	// Create the PR
 	if err := gh.CreatePr("gutenberg-mobile", &pr); err != nil {
@@ -162,6 +172,8 @@ func CreateGbmPR(build Build) (gh.PullRequest, error) {
 	if pr.Number == 0 {
 		return pr, fmt.Errorf("failed to create the PR")
 	}
+	report.RecordPr("gutenberg-mobile", pr)
+	AssignAndRequestReviews("gutenberg-mobile", &pr)
 
 	return pr, nil
 }
@@ -292,19 +304,31 @@ func getReleaseNotes(dir string, gbmPr *gh.PullRequest) ([]byte, error) {
 	return rn, nil
 }
 
-func updateGbSubmodule(gbBranch, dir string, git shell.GitCmds) error {
+func updateGbSubmodule(build Build, gbBranch string, git shell.GitCmds) error {
 	console.Info("Updating Gutenberg submodule")
 	// Create a git client for Gutenberg submodule so the Gutenberg ref can be
 	// updated to the correct branch
-	gbSp := shell.CmdProps{Dir: filepath.Join(dir, "gutenberg"), Verbose: true}
+	gbSp := build.shellProps(filepath.Join(build.Dir, "gutenberg"))
 	gbGit := shell.NewGitCmd(gbSp)
 
-	if err := gbGit.Fetch(gbBranch); err != nil {
-		return fmt.Errorf("error fetching the Gutenberg branch: %v", err)
-	}
-
-	if err := gbGit.Switch(gbBranch); err != nil {
-		return fmt.Errorf("error checking out the Gutenberg branch: %v", err)
+	if repo.ForkOwner != "" {
+		// The Gutenberg release branch was pushed to the fork
+		if err := addForkRemote("gutenberg", gbGit); err != nil {
+			return fmt.Errorf("error adding the Gutenberg fork: %v", err)
+		}
+		if err := gbGit.FetchRemote(repo.ForkRemote, gbBranch); err != nil {
+			return fmt.Errorf("error fetching the Gutenberg branch from the fork: %v", err)
+		}
+		if err := gbGit.Switch("-c", gbBranch, repo.ForkRemote+"/"+gbBranch); err != nil {
+			return fmt.Errorf("error checking out the Gutenberg branch: %v", err)
+		}
+	} else {
+		if err := gbGit.Fetch(gbBranch); err != nil {
+			return fmt.Errorf("error fetching the Gutenberg branch: %v", err)
+		}
+		if err := gbGit.Switch(gbBranch); err != nil {
+			return fmt.Errorf("error checking out the Gutenberg branch: %v", err)
+		}
 	}
 
 	if err := git.CommitAll("Release script: Update gutenberg submodule"); err != nil {
@@ -313,11 +337,12 @@ func updateGbSubmodule(gbBranch, dir string, git shell.GitCmds) error {
 	return nil
 }
 
-func updateXcFramework(version, dir string, git shell.GitCmds) error {
+func updateXcFramework(build Build, git shell.GitCmds) error {
+	version := build.Version.String()
 	console.Info("Update XCFramework builders project Podfile.lock")
 
 	// set up a shell command for the ios-xcframework directory
-	xcSp := shell.CmdProps{Dir: fmt.Sprintf("%s/ios-xcframework", dir), Verbose: true}
+	xcSp := build.shellProps(filepath.Join(build.Dir, "ios-xcframework"))
 
 	bundle := shell.NewBundlerCmd(xcSp)
 
@@ -327,7 +352,9 @@ func updateXcFramework(version, dir string, git shell.GitCmds) error {
 	}
 
 	// Run `bundle exec pod install``
-	if err := bundle.PodInstall(); err != nil {
+	// pod install can hang on a bad network, so limit how long it can take
+	xcSp.Timeout = PodInstallTimeout
+	if err := shell.NewBundlerCmd(xcSp).PodInstall(); err != nil {
 		return fmt.Errorf("error running bundle exec pod install: %v", err)
 	}
 
@@ -0,0 +1,188 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/semver"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+func TestUpdateXcFramework(t *testing.T) {
+
+	t.Run("It installs the pods and commits the lock file", func(t *testing.T) {
+		version, _ := semver.NewSemVer("1.109.0")
+		// a dirty working tree so there is something to commit
+		fake := shell.NewFake().On("git status", "1 .M N... 100644 100644 100644 a1 a1 Podfile.lock\x00", 0)
+		build := Build{Dir: "/gbm", Version: version, Shell: fake}
+		git := shell.NewGitCmd(build.shellProps(build.Dir))
+
+		if err := updateXcFramework(build, git); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := []string{
+			"bundle install",
+			"bundle exec pod install",
+			"git status --porcelain=v2 --branch -z",
+			"git commit -am Release script: Sync XCFramework `Podfile.lock` with 1.109.0",
+		}
+		if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", got, want)
+		}
+		assertEqual(t, fake.Calls()[0].Dir, "/gbm/ios-xcframework")
+	})
+}
+
+func TestUpdateGbSubmodule(t *testing.T) {
+
+	t.Run("It switches the submodule to the release branch", func(t *testing.T) {
+		fake := shell.NewFake().On("git status", "1 .M S.M. 160000 160000 160000 a1 a1 gutenberg\x00", 0)
+		build := Build{Dir: "/gbm", Shell: fake}
+		git := shell.NewGitCmd(build.shellProps(build.Dir))
+
+		if err := updateGbSubmodule(build, "rnmobile/release_1.109.0", git); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := []string{
+			"git remote set-branches origin *",
+			"git fetch origin rnmobile/release_1.109.0",
+			"git switch rnmobile/release_1.109.0",
+			"git status --porcelain=v2 --branch -z",
+			"git commit -am Release script: Update gutenberg submodule",
+		}
+		if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", got, want)
+		}
+		assertEqual(t, fake.Calls()[1].Dir, "/gbm/gutenberg")
+	})
+}
+
+func TestUpdateGbSubmoduleFromFork(t *testing.T) {
+
+	t.Run("It switches the submodule to the release branch on the fork", func(t *testing.T) {
+		t.Cleanup(repo.InitOrgs)
+		t.Setenv("GBM_FORK", "octocat")
+		repo.InitOrgs()
+
+		fake := shell.NewFake().On("git status", "1 .M S.M. 160000 160000 160000 a1 a1 gutenberg\x00", 0)
+		build := Build{Dir: "/gbm", Shell: fake}
+		git := shell.NewGitCmd(build.shellProps(build.Dir))
+
+		if err := updateGbSubmodule(build, "rnmobile/release_1.109.0", git); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got := fake.Commands()
+		want := []string{
+			"git remote",
+			"git remote add fork " + repo.GetForkRepoHttpsPath("gutenberg"),
+			"git fetch fork rnmobile/release_1.109.0",
+			"git switch -c rnmobile/release_1.109.0 fork/rnmobile/release_1.109.0",
+		}
+		if !reflect.DeepEqual(got[:len(want)], want) {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	})
+}
+
+func TestCreateGbmPR(t *testing.T) {
+	setup := func(t *testing.T) (Build, *shell.Fake, *gh.Fake) {
+		t.Helper()
+		t.Setenv("CI", "true")
+		console.Yes = true
+		t.Cleanup(func() { console.Yes = false })
+
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "RELEASE-NOTES.txt"), []byte("Unreleased\n---\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		version, _ := semver.NewSemVer("1.109.0")
+		fake := shell.NewFake().
+			On("git remote get-url origin", "https://github.com/"+repo.GetOrg("gutenberg-mobile")+"/gutenberg-mobile.git\n", 0).
+			On("git rev-parse", "1234567890abcdef\n", 0).
+			On("git status", "# branch.head release/1.109.0\x00", 0).
+			On("git ls-remote", "", 2)
+		build := Build{Dir: dir, Version: version, Local: true, Base: gh.Repo{Ref: "trunk"}, Shell: fake}
+		return build, fake, fakeGitHub(t)
+	}
+
+	t.Run("It prepares the release branch and creates the PR", func(t *testing.T) {
+		build, fake, api := setup(t)
+		useTemplates(t)
+		gbOrg, gbmOrg := repo.GetOrg("gutenberg"), repo.GetOrg("gutenberg-mobile")
+		api.On("GET repos/"+gbOrg+"/gutenberg/git/ref/heads/rnmobile/release_1.109.0", 200, gh.Ref{Ref: "refs/heads/rnmobile/release_1.109.0"}).
+			On("POST repos/"+gbmOrg+"/gutenberg-mobile/pulls", 201, gh.PullRequest{Number: 42})
+
+		pr, err := CreateGbmPR(build)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Number != 42 {
+			t.Fatalf("Expected the created PR, got %v", pr)
+		}
+
+		data, err := os.ReadFile(filepath.Join(build.Dir, "RELEASE-NOTES.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, string(data), "Unreleased\n---\n\n1.109.0\n---\n")
+
+		want := []string{
+			"git switch -c release/1.109.0 1234567890abcdef",
+			"git switch rnmobile/release_1.109.0",
+			"npm install",
+			"npm run i18n:update",
+			"bundle exec pod install",
+			"git push --set-upstream origin HEAD:refs/heads/release/1.109.0",
+		}
+		if got := filterCommands(fake.Commands(), want); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", fake.Commands(), want)
+		}
+		if !api.Sent("GET repos/" + gbmOrg + "/gutenberg-mobile/git/ref/heads/release/1.109.0") {
+			t.Fatalf("Expected the release branch to be checked, got %v", api.Requests())
+		}
+	})
+
+	t.Run("It checks the release branches on the fork", func(t *testing.T) {
+		t.Cleanup(repo.InitOrgs)
+		t.Setenv("GBM_FORK", "octocat")
+		repo.InitOrgs()
+
+		build, _, api := setup(t)
+		api.On("GET repos/octocat/gutenberg/git/ref/heads/rnmobile/release_1.109.0", 200, gh.Ref{Ref: "refs/heads/rnmobile/release_1.109.0"}).
+			On("POST repos/"+repo.GetOrg("gutenberg-mobile")+"/gutenberg-mobile/pulls", 201, gh.PullRequest{Number: 42})
+
+		pr, err := CreateGbmPR(build)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Number != 42 {
+			t.Fatalf("Expected the created PR, got %v", pr)
+		}
+		if !api.Sent("GET repos/octocat/gutenberg-mobile/git/ref/heads/release/1.109.0") {
+			t.Fatalf("Expected the release branch to be checked on the fork, got %v", api.Requests())
+		}
+		if api.Sent("GET repos/" + repo.GetOrg("gutenberg") + "/gutenberg/git/ref") {
+			t.Fatalf("Expected the upstream Gutenberg branch not to be checked, got %v", api.Requests())
+		}
+	})
+}
+
+// Replaces the GitHub API with a fake for the test
+func fakeGitHub(t *testing.T) *gh.Fake {
+	t.Helper()
+	t.Setenv("GH_TOKEN", "test")
+	fake := gh.NewFake()
+	prev := gh.Transport
+	gh.Transport = fake
+	t.Cleanup(func() { gh.Transport = prev })
+	return fake
+}
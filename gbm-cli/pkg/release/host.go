@@ -0,0 +1,75 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+)
+
+const HostReleaseBranchName = "release/%s"
+
+var hostVersionRe = regexp.MustCompile(`^(\d+)\.(\d+)$`)
+
+// Returns the host app release branch for a version like 24.3
+func HostReleaseBranch(hostVersion string) string {
+	return fmt.Sprintf(HostReleaseBranchName, hostVersion)
+}
+
+// FindLatestHostVersion returns the version of the most recent `release/X.YY` branch of a host app repo.
+// iOS and Android are cut independently so the versions can differ.
+func FindLatestHostVersion(rpo string) (string, error) {
+	refs, err := gh.ListRefs(rpo, "heads/release/")
+	if err != nil {
+		return "", fmt.Errorf("unable to list the release branches on %s: %v", rpo, err)
+	}
+
+	branches := []string{}
+	for _, r := range refs {
+		branches = append(branches, strings.TrimPrefix(r.Ref, "refs/heads/"))
+	}
+
+	version := LatestHostVersion(branches)
+	if version == "" {
+		return "", fmt.Errorf("no release/X.YY branches found on %s", rpo)
+	}
+	return version, nil
+}
+
+// LatestHostVersion returns the highest X.YY version of the `release/X.YY` branches.
+// Branches not matching that form (e.g. release/24.3.1) are ignored.
+func LatestHostVersion(branches []string) string {
+	latest := ""
+	var major, minor int
+
+	for _, b := range branches {
+		m := hostVersionRe.FindStringSubmatch(strings.TrimPrefix(b, "release/"))
+		if m == nil || !strings.HasPrefix(b, "release/") {
+			continue
+		}
+		ma, _ := strconv.Atoi(m[1])
+		mi, _ := strconv.Atoi(m[2])
+		if latest == "" || ma > major || (ma == major && mi > minor) {
+			latest, major, minor = m[0], ma, mi
+		}
+	}
+	return latest
+}
+
+// ValidateHostVersion checks the host version has the X.YY form and the release branch exists on the repo
+func ValidateHostVersion(rpo, hostVersion string) error {
+	if !hostVersionRe.MatchString(hostVersion) {
+		return fmt.Errorf("invalid host version %s. Host versions must have a `Major.Minor` form", hostVersion)
+	}
+	branch := HostReleaseBranch(hostVersion)
+	exists, err := refExists(rpo, "heads/"+branch)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the branch %s does not exist on %s", branch, rpo)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+func TestLatestHostVersion(t *testing.T) {
+
+	t.Run("It returns the highest release branch version", func(t *testing.T) {
+		got := LatestHostVersion([]string{"release/24.2", "release/24.10", "release/9.9", "release/24.3"})
+		assertEqual(t, got, "24.10")
+	})
+
+	t.Run("It ignores branches that are not X.YY releases", func(t *testing.T) {
+		got := LatestHostVersion([]string{"release/24.2", "release/25.0.1", "release/alpha", "feature/30.0"})
+		assertEqual(t, got, "24.2")
+	})
+
+	t.Run("It returns an empty version if there are no release branches", func(t *testing.T) {
+		got := LatestHostVersion([]string{"trunk"})
+		assertEqual(t, got, "")
+	})
+}
+
+func TestValidateHostVersion(t *testing.T) {
+	ref := "GET repos/" + repo.GetOrg("WordPress-iOS") + "/WordPress-iOS/git/ref/heads/release/24.3"
+
+	t.Run("It accepts an existing release branch", func(t *testing.T) {
+		fakeGitHub(t).On(ref, 200, gh.Ref{})
+		if err := ValidateHostVersion("WordPress-iOS", "24.3"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("It rejects a missing release branch", func(t *testing.T) {
+		fakeGitHub(t)
+		err := ValidateHostVersion("WordPress-iOS", "24.3")
+		if err == nil || !strings.Contains(err.Error(), "does not exist") {
+			t.Fatalf("Expected a missing branch error, got %v", err)
+		}
+	})
+
+	t.Run("It returns the errors of the lookup", func(t *testing.T) {
+		fakeGitHub(t).On(ref, 401, `{"message": "Bad credentials"}`)
+		err := ValidateHostVersion("WordPress-iOS", "24.3")
+		if err == nil || strings.Contains(err.Error(), "does not exist") {
+			t.Fatalf("Expected the lookup error, got %v", err)
+		}
+	})
+}
+
+func assertEqual(t testing.TB, got, want string) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
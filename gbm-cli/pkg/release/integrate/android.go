@@ -1,14 +1,12 @@
 package integrate
 
 import (
-	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
-	"regexp"
 
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gbm"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gradle"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
 
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
@@ -16,42 +14,47 @@ import (
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
 )
 
+// GutenbergMobileVersionKeys are the names the gutenberg-mobile version is declared under
+// in the WordPress-Android gradle config, the ext property and the version catalog entry.
+var GutenbergMobileVersionKeys = []string{"gutenbergMobileVersion", "gutenberg-mobile"}
+
 type AndroidIntegration struct {
 }
 
-func (ai AndroidIntegration) UpdateGutenbergConfig(dir string, gbmPr gh.PullRequest) error {
-	sp := shell.CmdProps{Dir: dir, Verbose: true}
+func (ai AndroidIntegration) UpdateGutenbergConfig(dir string, ri ReleaseIntegration) error {
+	gbmPr := ri.GbmPr
+	sp := ri.shellProps(dir)
 	git := shell.NewGitCmd(sp)
 	prId := gbmPr.Number
 	prSha := gbmPr.Head.Sha
 
-	configPath := filepath.Join(dir, "build.gradle")
-	config, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	re := regexp.MustCompile(`(gutenbergMobileVersion\s*=\s*)'(?:.*)'`)
-	if match := re.Match(config); !match {
-		return errors.New("cannot find a version in the gradle file")
-	}
-
-	var replace string
+	var version string
 	if releaseAvailable, err := useRelease(gbmPr.ReleaseVersion); err != nil {
 		return fmt.Errorf("unable to check for a release: %s", err)
 	} else if releaseAvailable {
 		console.Info("Updating gutenberg-mobile ref to the tag v%s", gbmPr.ReleaseVersion)
-		replace = fmt.Sprintf(`$1'v%s'`, fmt.Sprint(gbmPr.ReleaseVersion))
+		version = "v" + gbmPr.ReleaseVersion
 	} else {
 		console.Info("Updating gutenberg-mobile ref to the commit %s", prSha)
-		replace = fmt.Sprintf(`$1'%v-%s'`, prId, prSha)
+		version = fmt.Sprintf("%v-%s", prId, prSha)
+	}
+
+	prev, err := gradle.UpdateVersion(dir, version, GutenbergMobileVersionKeys...)
+	if err != nil {
+		return err
 	}
-	config = re.ReplaceAll(config, []byte(replace))
+	console.Info("Updated %s from %s to %s", prev.Path, prev.Value, version)
 
-	if err := os.WriteFile(configPath, config, 0644); err != nil {
+	if err := ri.Deps.Run(sp, ai.DependencySteps()); err != nil {
 		return err
 	}
-	return git.CommitAll("Release script: Update build.gradle gutenbergMobileVersion to ref")
+
+	return git.CommitAll("Release script: Update %s %s to ref", filepath.Base(prev.Path), prev.Key)
+}
+
+// Android resolves gutenberg-mobile at build time so there are no steps by default
+func (ai AndroidIntegration) DependencySteps() []Step {
+	return nil
 }
 
 func (ai AndroidIntegration) GetRepo() string {
@@ -66,6 +69,26 @@ func (ai AndroidIntegration) GetPr(ri ReleaseIntegration) (gh.PullRequest, error
 	return gh.PullRequest{}, nil
 }
 
+func (ai AndroidIntegration) ConfigRef(pr gh.PullRequest) (string, error) {
+	configs := map[string]string{}
+	for _, f := range gradle.ConfigFiles {
+		config, err := gh.GetFileContent(ai.GetRepo(), pr.Head.Sha, filepath.ToSlash(f))
+		if gh.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		configs[f] = config
+	}
+
+	m, err := gradle.FindVersionIn(configs, GutenbergMobileVersionKeys...)
+	if err != nil {
+		return "", err
+	}
+	return m.Value, nil
+}
+
 func (ai AndroidIntegration) GbPublished(gbmPr gh.PullRequest) (bool, error) {
 	published, err := gbm.AndroidGbmBuildPublished(gbmPr)
 	if err != nil {
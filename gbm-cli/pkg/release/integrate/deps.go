@@ -0,0 +1,100 @@
+package integrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+// Step is a command run after the Gutenberg config is updated and before it is committed
+type Step struct {
+	Name string
+	Cmd  []string
+}
+
+// IosDependencySteps resolve the Pods and lockfiles for the updated gutenberg-mobile ref.
+// They need a fully provisioned Mac, see Deps for skipping or wrapping them.
+var IosDependencySteps = []Step{
+	{Name: "bundle install", Cmd: []string{"bundle", "install"}},
+	{Name: "rake dependencies", Cmd: []string{"rake", "dependencies"}},
+}
+
+// Deps configures the post update pipeline of a target.
+type Deps struct {
+	// Skip the dependency steps, leaving lockfiles to be regenerated by CI
+	Skip bool
+
+	// Command every step is run through, e.g. `docker run --rm -v {dir}:/src -w /src image`.
+	// See shell.CmdProps.Wrapper
+	Wrapper string
+
+	// Steps replaces the target's default steps when set, see ParseSteps
+	Steps []Step
+}
+
+// ParseSteps parses command lines, e.g. from --ios-deps-step 'bundle exec pod install', into steps named after them.
+// No command lines return nil, which keeps the target's default steps.
+func ParseSteps(cmds []string) ([]Step, error) {
+	var steps []Step
+	for _, c := range cmds {
+		args, err := shell.SplitWords(c)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the dependency step %s: %v", c, err)
+		}
+		if len(args) == 0 {
+			return nil, fmt.Errorf("the dependency step %q has no command", c)
+		}
+		steps = append(steps, Step{Name: strings.Join(args, " "), Cmd: args})
+	}
+	return steps, nil
+}
+
+func (d Deps) steps(defaults []Step) []Step {
+	if d.Steps != nil {
+		return d.Steps
+	}
+	return defaults
+}
+
+// Skipped returns the names of the steps that were not run
+func (d Deps) Skipped(defaults []Step) []string {
+	skipped := []string{}
+	if !d.Skip {
+		return skipped
+	}
+	for _, s := range d.steps(defaults) {
+		skipped = append(skipped, s.Name)
+	}
+	return skipped
+}
+
+// Run runs the steps with the shell props, or logs them if they are skipped
+func (d Deps) Run(sp shell.CmdProps, defaults []Step) error {
+	steps := d.steps(defaults)
+	if len(steps) == 0 {
+		return nil
+	}
+
+	if d.Skip {
+		console.Warn("Skipping dependency steps: %s. CI will need to regenerate the lockfiles", strings.Join(d.Skipped(defaults), ", "))
+		return nil
+	}
+
+	wrapper, err := shell.SplitWords(d.Wrapper)
+	if err != nil {
+		return fmt.Errorf("error parsing the deps wrapper: %v", err)
+	}
+	sp.Wrapper = wrapper
+	for _, s := range steps {
+		if len(s.Cmd) == 0 {
+			return fmt.Errorf("step %s has no command", s.Name)
+		}
+		console.Info("Running %s", s.Name)
+		if err := shell.NewExecCmd(s.Cmd[0], sp).Exec(s.Cmd[1:]...); err != nil {
+			return fmt.Errorf("error running %s: %v", s.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package integrate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+func TestDeps(t *testing.T) {
+
+	t.Run("It lists the skipped steps", func(t *testing.T) {
+		d := Deps{Skip: true}
+		got := d.Skipped(IosDependencySteps)
+		want := []string{"bundle install", "rake dependencies"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("It does not list steps that are run", func(t *testing.T) {
+		d := Deps{}
+		if got := d.Skipped(IosDependencySteps); len(got) != 0 {
+			t.Fatalf("Expected no skipped steps, got %v", got)
+		}
+	})
+
+	t.Run("It prefers the configured steps over the target defaults", func(t *testing.T) {
+		d := Deps{Skip: true, Steps: []Step{{Name: "pod install", Cmd: []string{"pod", "install"}}}}
+		got := d.Skipped(IosDependencySteps)
+		if !reflect.DeepEqual(got, []string{"pod install"}) {
+			t.Fatalf("got %v", got)
+		}
+	})
+
+	t.Run("It runs the steps through the wrapper", func(t *testing.T) {
+		d := Deps{Wrapper: "env", Steps: []Step{{Name: "true", Cmd: []string{"true"}}}}
+		assertNoError(t, d.Run(shell.CmdProps{Dir: t.TempDir()}, nil))
+	})
+
+	t.Run("It keeps the quoted arguments of the wrapper together", func(t *testing.T) {
+		d := Deps{Wrapper: `sh -c '"$0" "$@"'`, Steps: []Step{{Name: "true", Cmd: []string{"true"}}}}
+		assertNoError(t, d.Run(shell.CmdProps{Dir: t.TempDir()}, nil))
+	})
+
+	t.Run("It parses the configured steps", func(t *testing.T) {
+		steps, err := ParseSteps([]string{"bundle install", `sh -c 'rake dependencies'`})
+		assertNoError(t, err)
+		want := []Step{
+			{Name: "bundle install", Cmd: []string{"bundle", "install"}},
+			{Name: "sh -c rake dependencies", Cmd: []string{"sh", "-c", "rake dependencies"}},
+		}
+		if !reflect.DeepEqual(steps, want) {
+			t.Fatalf("got %v want %v", steps, want)
+		}
+
+		if steps, err := ParseSteps(nil); err != nil || steps != nil {
+			t.Fatalf("Expected the defaults to be kept, got %v %v", steps, err)
+		}
+		if _, err := ParseSteps([]string{"rake 'dependencies"}); err == nil {
+			t.Fatal("Expected an error for an unterminated quote")
+		}
+	})
+
+	t.Run("It returns an error when a step fails", func(t *testing.T) {
+		d := Deps{Steps: []Step{{Name: "false", Cmd: []string{"false"}}}}
+		assertError(t, d.Run(shell.CmdProps{Dir: t.TempDir()}, nil))
+	})
+}
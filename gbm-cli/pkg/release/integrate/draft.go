@@ -0,0 +1,46 @@
+package integrate
+
+import (
+	"fmt"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
+)
+
+// ReleaseRef is the gutenberg-mobile ref the host app configs point to once the release is published
+func ReleaseRef(version string) string {
+	return "v" + version
+}
+
+// MarkReady takes a draft integration PR out of draft once the GBM release is published
+// and the Gutenberg config on the PR points to its tag. Returns whether the PR was marked ready.
+func MarkReady(target Target, version string, pr *gh.PullRequest) (bool, error) {
+	if pr.Number == 0 || !pr.Draft || !releasePublished(version) {
+		return false, nil
+	}
+
+	ref, err := target.ConfigRef(*pr)
+	if err != nil {
+		return false, fmt.Errorf("error reading the gutenberg config of %s: %v", pr.Url, err)
+	}
+	if !pointsToRelease(ref, version) {
+		console.Info("%s points to %s, run `integrate --update` to point it to %s", pr.Url, ref, ReleaseRef(version))
+		return false, nil
+	}
+
+	if err := gh.MarkReadyForReview(pr); err != nil {
+		return false, fmt.Errorf("error marking %s ready for review: %v", pr.Url, err)
+	}
+	console.Info("Marked %s ready for review", pr.Url)
+	return true, nil
+}
+
+func pointsToRelease(ref, version string) bool {
+	return ref == ReleaseRef(version)
+}
+
+func releasePublished(version string) bool {
+	rel, err := release.GetGbmRelease(version)
+	return err == nil && rel.PublishedAt != ""
+}
@@ -6,9 +6,11 @@ import (
 
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/mirror"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/release"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/render"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/report"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
 )
 
@@ -27,13 +29,30 @@ type ReleaseIntegration struct {
 	HeadBranch string
 	Target     Target
 	GbmPr      gh.PullRequest
+	Deps       Deps
+
+	// Update pushes the config to the release branch of an existing PR
+	// and marks the PR ready for review once it points to the release.
+	Update bool
+
+	// Shell runs the git and dependency commands. Defaults to executing them.
+	Shell shell.Backend
+}
+
+// Returns the props for the shell clients of the integration running in dir
+func (ri ReleaseIntegration) shellProps(dir string) shell.CmdProps {
+	return shell.CmdProps{Dir: dir, Verbose: true, Backend: ri.Shell}
 }
 
 type Target interface {
-	UpdateGutenbergConfig(dir string, gbmPr gh.PullRequest) error
+	UpdateGutenbergConfig(dir string, ri ReleaseIntegration) error
+	DependencySteps() []Step
 	GetRepo() string
 	GetPr(ri ReleaseIntegration) (gh.PullRequest, error)
 	GbPublished(gh.PullRequest) (bool, error)
+
+	// ConfigRef returns the gutenberg-mobile ref the config on the head of the PR points to
+	ConfigRef(pr gh.PullRequest) (string, error)
 }
 
 func (ri *ReleaseIntegration) Run(dir string) (gh.PullRequest, error) {
@@ -60,36 +79,53 @@ func (ri *ReleaseIntegration) Run(dir string) (gh.PullRequest, error) {
 
 	gbmPr := ri.GbmPr
 
-	git := shell.NewGitCmd(shell.CmdProps{Dir: dir, Verbose: true})
+	// Check if the PR already exists
+	existing, err := ri.Target.GetPr(*ri)
+	if err != nil {
+		return pr, fmt.Errorf("error getting the PR: %v", err)
+	}
+	if existing.Number != 0 && !ri.Update {
+		console.Info("PR already exists: %s, run with --update to update it", existing.Url)
+		report.RecordPr(rpo, existing)
+		return existing, nil
+	}
+
+	git := shell.NewGitCmd(ri.shellProps(dir))
 
 	// Clone repo
-	if err := ri.cloneRepo(git); err != nil {
+	if err := ri.cloneRepo(dir, git); err != nil {
 		return pr, fmt.Errorf("error cloning the %s repository: %v", rpo, err)
 	}
+	release.RecordBase(rpo, ri.BaseBranch, git)
 
 	// Update gutenberg config
-	if err := ri.Target.UpdateGutenbergConfig(dir, gbmPr); err != nil {
+	if err := ri.Target.UpdateGutenbergConfig(dir, *ri); err != nil {
 		return pr, fmt.Errorf("error updating the gutenberg config: %v", err)
 	}
 
-	if err := git.Push(); err != nil {
+	if err := release.PushBranch(rpo, git); err != nil {
 		return pr, fmt.Errorf("error pushing changes: %v", err)
 	}
-
-	// Check if the PR already exists
-	pr, err := ri.Target.GetPr(*ri)
-	if err != nil {
-		return pr, fmt.Errorf("error getting the PR: %v", err)
-	}
-
-	if pr.Number != 0 {
-		console.Info("PR already exists: %s", pr.Url)
-		return pr, nil
+	release.RecordHead(rpo, git)
+
+	if existing.Number != 0 {
+		console.Info("Updated the PR %s", existing.Url)
+		// The PR was found before the push, its config is read at the pushed head
+		if sha, err := git.HeadSha(); err != nil {
+			console.Warn("Unable to get the pushed head of %s: %v", existing.Url, err)
+		} else {
+			existing.Head.Sha = sha
+		}
+		if _, err := MarkReady(ri.Target, ri.Version, &existing); err != nil {
+			console.Warn(err.Error())
+		}
+		report.RecordPr(rpo, existing)
+		return existing, nil
 	}
 
 	// Confirm PR creation
 	prompt := fmt.Sprintf("\nReady to create the PR on %s/%s?", org, rpo)
-	if cont := console.Confirm(prompt); !cont {
+	if cont := console.Confirm("create_integration_pr", prompt); !cont {
 		console.Info("Bye 👋")
 		return pr, errors.New("exiting before creating PR")
 	}
@@ -98,6 +134,7 @@ func (ri *ReleaseIntegration) Run(dir string) (gh.PullRequest, error) {
 	if err != nil {
 		return pr, fmt.Errorf("error creating the PR: %v", err)
 	}
+	report.RecordPr(rpo, pr)
 
 	// Create after branch
 	if err := ri.createAfterBranch(git); err != nil {
@@ -108,20 +145,25 @@ func (ri *ReleaseIntegration) Run(dir string) (gh.PullRequest, error) {
 }
 
 // Clone the repo at the configured base branch or at the release branch if it already exists.
-func (ri *ReleaseIntegration) cloneRepo(git shell.GitCmds) error {
+func (ri *ReleaseIntegration) cloneRepo(dir string, git shell.GitCmds) error {
 	// Check if release branch already exists
 	rpo := ri.Target.GetRepo()
-	repoPath := repo.GetRepoHttpsPath(rpo)
 
 	branch := fmt.Sprintf(release.IntegrateBranchName, ri.Version)
-	exists, err := gh.SearchBranch(rpo, branch)
-	if err != nil {
-		return err
+
+	// With a fork the release branch lives on the fork, so it's recreated from the base
+	// and PushBranch asks before overwriting it
+	exists := gh.Branch{}
+	if repo.ForkOwner == "" {
+		var err error
+		if exists, err = gh.SearchBranch(rpo, branch); err != nil {
+			return err
+		}
 	}
 
 	if (exists != gh.Branch{}) {
 		console.Info("Cloning repo at release branch %s", branch)
-		if err := git.Clone(repoPath, "-b", branch, "--depth=1", "."); err != nil {
+		if err := mirror.Clone(rpo, branch, ri.shellProps(dir), false); err != nil {
 			return err
 		}
 	} else {
@@ -132,7 +174,7 @@ func (ri *ReleaseIntegration) cloneRepo(git shell.GitCmds) error {
 		}
 
 		console.Info("Cloning repo at base branch %s", base)
-		if err := git.Clone("-b", base, "--depth=1", repoPath, "."); err != nil {
+		if err := mirror.Clone(rpo, base, ri.shellProps(dir), false); err != nil {
 			return err
 		}
 
@@ -148,6 +190,14 @@ func (ri *ReleaseIntegration) cloneRepo(git shell.GitCmds) error {
 func (ri *ReleaseIntegration) createAfterBranch(git shell.GitCmds) error {
 	rpo := ri.Target.GetRepo()
 	afterBranch := fmt.Sprintf(release.IntegrateAfterBranchName, ri.Version)
+
+	// PRs can't target branches on a fork, the after branch needs to be created upstream
+	if repo.ForkOwner != "" {
+		console.Warn("Skipping the after branch %s when using a fork, ask a maintainer of %s to create it", afterBranch, rpo)
+		report.RecordSkip(rpo, "after branch", "--fork")
+		return nil
+	}
+
 	// Check if branch exits
 	exists, err := gh.SearchBranch(rpo, afterBranch)
 	if err != nil {
@@ -172,7 +222,7 @@ func (ri *ReleaseIntegration) createAfterBranch(git shell.GitCmds) error {
 	if err := git.Switch("-c", afterBranch); err != nil {
 		return err
 	}
-	if err := git.Push(); err != nil {
+	if err := release.PushBranch(rpo, git); err != nil {
 		return err
 	}
 	return nil
@@ -186,7 +236,11 @@ func (ri *ReleaseIntegration) createPR(dir string, gbmPr gh.PullRequest) (gh.Pul
 	pr.Base.Ref = ri.BaseBranch
 	pr.Head.Ref = ri.HeadBranch
 
-	if err := renderPrBody(version, &pr, gbmPr); err != nil {
+	skipped := ri.Deps.Skipped(ri.Target.DependencySteps())
+	for _, step := range skipped {
+		report.RecordSkip(ri.Target.GetRepo(), step, "--skip-deps")
+	}
+	if err := renderPrBody(version, &pr, gbmPr, skipped); err != nil {
 		console.Info("Unable to render the GB PR body (err %s)", err)
 	}
 
@@ -194,24 +248,33 @@ func (ri *ReleaseIntegration) createPR(dir string, gbmPr gh.PullRequest) (gh.Pul
 		Name: release.IntegratePrLabel,
 	}}
 
+	// The PR stays a draft until the config points to the published release, see MarkReady
+	if !releasePublished(version) {
+		console.Info("Creating a draft PR until the v%s release is published", version)
+		pr.Draft = true
+	}
+
 	rpo := ri.Target.GetRepo()
 	gh.PreviewPr(rpo, dir, ri.BaseBranch, pr)
 
 	if err := gh.CreatePr(rpo, &pr); err != nil {
 		return pr, err
 	}
+	release.AssignAndRequestReviews(rpo, &pr)
 	return pr, nil
 }
 
-func renderPrBody(version string, pr *gh.PullRequest, gbmPr gh.PullRequest) error {
+func renderPrBody(version string, pr *gh.PullRequest, gbmPr gh.PullRequest, skippedDeps []string) error {
 	t := render.Template{
 		Path: "templates/release/integrate_pr_body.md",
 		Data: struct {
-			Version  string
-			GbmPrUrl string
+			Version     string
+			GbmPrUrl    string
+			SkippedDeps []string
 		}{
-			Version:  version,
-			GbmPrUrl: gbmPr.Url,
+			Version:     version,
+			GbmPrUrl:    gbmPr.Url,
+			SkippedDeps: skippedDeps,
 		},
 	}
 
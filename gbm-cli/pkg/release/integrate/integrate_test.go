@@ -1,6 +1,45 @@
 package integrate
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/mirror"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/render"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+// An Android target with the GBM build published and no existing PR, which would otherwise need the network
+type publishedAndroid struct {
+	AndroidIntegration
+}
+
+func (publishedAndroid) GbPublished(gh.PullRequest) (bool, error) {
+	return true, nil
+}
+
+func (publishedAndroid) GetPr(ReleaseIntegration) (gh.PullRequest, error) {
+	return gh.PullRequest{}, nil
+}
+
+// An iOS target with the GBM build published and an existing PR
+type existingIos struct {
+	IosIntegration
+	pr gh.PullRequest
+}
+
+func (existingIos) GbPublished(gh.PullRequest) (bool, error) {
+	return true, nil
+}
+
+func (i existingIos) GetPr(ReleaseIntegration) (gh.PullRequest, error) {
+	return i.pr, nil
+}
 
 func TestRun(t *testing.T) {
 	t.Run("It returns an error if no platform is specified", func(t *testing.T) {
@@ -9,6 +48,135 @@ func TestRun(t *testing.T) {
 		_, err := ri.Run("")
 		assertError(t, err)
 	})
+
+	t.Run("It updates the config and creates the integration PR", func(t *testing.T) {
+		setupRun(t)
+
+		dir := t.TempDir()
+		config := filepath.Join(dir, "build.gradle")
+		if err := os.WriteFile(config, []byte("ext {\n    gutenbergMobileVersion = 'v1.108.0'\n}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		rpo := repo.WordPressAndroidRepo
+		api := fakeGitHub(t).
+			On("POST repos/"+repo.GetOrg(rpo)+"/"+rpo+"/pulls", 201, gh.PullRequest{Number: 7})
+		fake := shell.NewFake().
+			On("git status", "# branch.head gutenberg/integrate_release_1.109.0\x00", 0).
+			On("git ls-remote", "", 2)
+
+		ri := ReleaseIntegration{
+			Version:    "1.109.0",
+			BaseBranch: "trunk",
+			HeadBranch: "gutenberg/integrate_release_1.109.0",
+			Target:     publishedAndroid{},
+			GbmPr:      gh.PullRequest{Number: 123, Head: gh.Repo{Sha: "abcdef"}, ReleaseVersion: "1.109.0"},
+			Shell:      fake,
+		}
+		pr, err := ri.Run(dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Number != 7 {
+			t.Fatalf("Expected the created PR, got %v", pr)
+		}
+
+		data, err := os.ReadFile(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "gutenbergMobileVersion = '123-abcdef'") {
+			t.Fatalf("Expected the config to point to the GBM PR commit, got %s", data)
+		}
+		assertRan(t, fake.Commands(),
+			"git clone "+repo.GetRepoHttpsPath(rpo)+" --branch trunk --depth=1 .",
+			"git switch -c gutenberg/integrate_release_1.109.0",
+			"git push --set-upstream origin HEAD:refs/heads/gutenberg/integrate_release_1.109.0",
+			"git switch -c gutenberg/after_1.109.0",
+		)
+		if !api.Sent("POST repos/" + repo.GetOrg(rpo) + "/" + rpo + "/pulls") {
+			t.Fatalf("Expected the PR to be created, got %v", api.Requests())
+		}
+	})
+}
+
+func TestRunUpdate(t *testing.T) {
+	t.Run("It points the config of the existing PR to the release and marks it ready", func(t *testing.T) {
+		setupRun(t)
+
+		dir := t.TempDir()
+		config := filepath.Join(dir, "Gutenberg", "config.yml")
+		assertNoError(t, os.MkdirAll(filepath.Dir(config), os.ModePerm))
+		assertNoError(t, os.WriteFile(config, []byte(gutenbergConfig("  commit: 0123456789abcdef0123456789abcdef01234567\n")), 0644))
+
+		rpo := repo.WordPressIosRepo
+		org := repo.GetOrg(rpo)
+		api := fakeGitHub(t).
+			On("GET repos/"+repo.GetOrg("gutenberg-mobile")+"/gutenberg-mobile/releases/tags/v1.109.0", 200, gh.Release{TagName: "v1.109.0", PublishedAt: "2023-10-20T10:15:00Z"}).
+			On("GET repos/"+org+"/"+rpo+"/contents/Gutenberg/config.yml?ref=0123456789abcdef0123456789abcdef01234567", 200, "not the updated config").
+			On("GET repos/"+org+"/"+rpo+"/contents/Gutenberg/config.yml?ref=fedcba9876543210fedcba9876543210fedcba98", 200, map[string]string{"content": gutenbergConfig("  tag: v1.109.0\n")}).
+			On("GET repos/wordpress-mobile/gutenberg-mobile/git/ref/tags/v1.109.0", 200, gh.Ref{Ref: "refs/tags/v1.109.0"}).
+			On("POST graphql", 200, `{"data":{"markPullRequestReadyForReview":{"pullRequest":{"isDraft":false}}}}`)
+		fake := shell.NewFake().
+			On("git status", "# branch.head gutenberg/integrate_release_1.109.0\x00", 0).
+			On("git ls-remote", "", 2).
+			On("git rev-parse", "fedcba9876543210fedcba9876543210fedcba98\n", 0)
+
+		existing := gh.PullRequest{
+			Number: 5,
+			NodeId: "PR_5",
+			Draft:  true,
+			Url:    "https://github.com/" + org + "/" + rpo + "/pull/5",
+			Head:   gh.Repo{Sha: "0123456789abcdef0123456789abcdef01234567"},
+		}
+		ri := ReleaseIntegration{
+			Version:    "1.109.0",
+			BaseBranch: "trunk",
+			HeadBranch: "gutenberg/integrate_release_1.109.0",
+			Target:     existingIos{pr: existing},
+			GbmPr:      gh.PullRequest{Number: 123, ReleaseVersion: "1.109.0"},
+			Deps:       Deps{Skip: true},
+			Update:     true,
+			Shell:      fake,
+		}
+		pr, err := ri.Run(dir)
+		assertNoError(t, err)
+
+		data, err := os.ReadFile(config)
+		assertNoError(t, err)
+		if !strings.Contains(string(data), "tag: v1.109.0") {
+			t.Fatalf("Expected the config to point to the release, got %s", data)
+		}
+		assertRan(t, fake.Commands(), "git push --set-upstream origin HEAD:refs/heads/gutenberg/integrate_release_1.109.0")
+		if !api.Sent("POST graphql") {
+			t.Fatalf("Expected the PR to be marked ready for review, got %v", api.Requests())
+		}
+		if pr.Number != 5 || pr.Draft {
+			t.Fatalf("Expected the existing PR out of draft, got %+v", pr)
+		}
+	})
+}
+
+func TestMarkReady(t *testing.T) {
+	t.Run("It only marks the config pointing to the release tag ready", func(t *testing.T) {
+		if !pointsToRelease("v1.109.0", "1.109.0") {
+			t.Fatal("Expected the tag to point to the release")
+		}
+		if pointsToRelease("6123-0123456789abcdef0123456789abcdef01234567", "1.109.0") {
+			t.Fatal("Expected a commit not to point to the release")
+		}
+		if pointsToRelease("v1.108.0", "1.109.0") {
+			t.Fatal("Expected another tag not to point to the release")
+		}
+	})
+
+	t.Run("It leaves PRs that are not drafts alone", func(t *testing.T) {
+		pr := gh.PullRequest{Number: 1, Draft: false}
+		marked, err := MarkReady(IosIntegration{}, "1.109.0", &pr)
+		if err != nil || marked {
+			t.Fatalf("Expected nothing to be done, got %v %v", marked, err)
+		}
+	})
 }
 
 func assertError(t *testing.T, err error) {
@@ -17,3 +185,46 @@ func assertError(t *testing.T, err error) {
 		t.Fatalf("Expected an error, got nil")
 	}
 }
+
+// assertRan checks that the commands in want ran in that order
+func assertRan(t *testing.T, cmds []string, want ...string) {
+	t.Helper()
+	i := 0
+	for _, c := range cmds {
+		if i < len(want) && c == want[i] {
+			i++
+		}
+	}
+	if i < len(want) {
+		t.Fatalf("Expected %q to run, got %v", want[i], cmds)
+	}
+}
+
+// Runs integrations without prompts, the mirror cache or the network
+func setupRun(t *testing.T) {
+	t.Helper()
+	t.Setenv("CI", "true")
+	console.Yes = true
+	mirrorEnabled, templates := mirror.Enabled, render.TemplateFS
+	mirror.Enabled = false
+	render.TemplateFS = os.DirFS(filepath.Join("..", "..", ".."))
+	t.Cleanup(func() {
+		console.Yes = false
+		mirror.Enabled, render.TemplateFS = mirrorEnabled, templates
+	})
+}
+
+// Replaces the GitHub API with a fake for the test
+func fakeGitHub(t *testing.T) *gh.Fake {
+	t.Helper()
+	t.Setenv("GH_TOKEN", "test")
+	fake := gh.NewFake()
+	prev := gh.Transport
+	gh.Transport = fake
+	t.Cleanup(func() { gh.Transport = prev })
+	return fake
+}
+
+func gutenbergConfig(ref string) string {
+	return "ref:\n" + ref + "github_org: wordpress-mobile\nrepo_name: gutenberg-mobile\n"
+}
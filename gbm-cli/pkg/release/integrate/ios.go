@@ -18,8 +18,9 @@ type IosIntegration struct {
 	Repo string
 }
 
-func (ii IosIntegration) UpdateGutenbergConfig(dir string, gbmPr gh.PullRequest) error {
-	sp := shell.CmdProps{Dir: dir, Verbose: true}
+func (ii IosIntegration) UpdateGutenbergConfig(dir string, ri ReleaseIntegration) error {
+	gbmPr := ri.GbmPr
+	sp := ri.shellProps(dir)
 	git := shell.NewGitCmd(sp)
 
 	// @TODO update github org although not sure it's useful here
@@ -49,26 +50,31 @@ func (ii IosIntegration) UpdateGutenbergConfig(dir string, gbmPr gh.PullRequest)
 		return err
 	}
 
-	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+	// Never write a config that would not resolve when running `rake dependencies`
+	gbConfig, err := ParseGutenbergConfig(config)
+	if err != nil {
 		return err
 	}
-	sp = shell.CmdProps{Dir: dir, Verbose: true}
-	bundle := shell.NewBundlerCmd(sp)
+	if err := gbConfig.VerifyRef(); err != nil {
+		return fmt.Errorf("invalid Gutenberg config ref: %v", err)
+	}
+	previewConfigDiff("Gutenberg/config.yml", string(buf), config)
 
-	console.Info("Running bundle install")
-	if err := bundle.Install(); err != nil {
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
 		return err
 	}
 
-	console.Info("Running rake dependencies")
-	rake := shell.NewRakeCmd(sp)
-	if err := rake.Dependencies(); err != nil {
+	if err := ri.Deps.Run(sp, ii.DependencySteps()); err != nil {
 		return err
 	}
 
 	return git.CommitAll("Release script: Update gutenberg-mobile ref %s", gbmPr.ReleaseVersion)
 }
 
+func (ii IosIntegration) DependencySteps() []Step {
+	return IosDependencySteps
+}
+
 func (ii IosIntegration) GetRepo() string {
 	return repo.WordPressIosRepo
 }
@@ -81,6 +87,18 @@ func (ia IosIntegration) GetPr(ri ReleaseIntegration) (gh.PullRequest, error) {
 	return gh.PullRequest{}, nil
 }
 
+func (ia IosIntegration) ConfigRef(pr gh.PullRequest) (string, error) {
+	config, err := gh.GetFileContent(ia.GetRepo(), pr.Head.Sha, "Gutenberg/config.yml")
+	if err != nil {
+		return "", err
+	}
+	gbConfig, err := ParseGutenbergConfig(config)
+	if err != nil {
+		return "", err
+	}
+	return gbConfig.RefName(), nil
+}
+
 func (ia IosIntegration) GbPublished(gbmPr gh.PullRequest) (bool, error) {
 	published, err := gbm.IosGbmBuildPublished(gbmPr)
 	if err != nil {
@@ -0,0 +1,101 @@
+package integrate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kylelemons/godebug/diff"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"gopkg.in/yaml.v3"
+)
+
+// GutenbergConfig mirrors the schema of WordPress-iOS Gutenberg/config.yml
+type GutenbergConfig struct {
+	Ref struct {
+		Tag    string `yaml:"tag"`
+		Commit string `yaml:"commit"`
+	} `yaml:"ref"`
+	GithubOrg string `yaml:"github_org"`
+	RepoName  string `yaml:"repo_name"`
+}
+
+var shaRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ParseGutenbergConfig decodes the config and checks it against the expected schema.
+// It does not reach out to GitHub, see VerifyRef for that.
+func ParseGutenbergConfig(config string) (GutenbergConfig, error) {
+	c := GutenbergConfig{}
+	if err := yaml.Unmarshal([]byte(config), &c); err != nil {
+		return c, fmt.Errorf("invalid yaml: %v", err)
+	}
+
+	errs := []string{}
+	tag, commit := c.Ref.Tag, c.Ref.Commit
+
+	switch {
+	case tag == "" && commit == "":
+		errs = append(errs, "ref must have a tag or a commit")
+	case tag != "" && commit != "":
+		errs = append(errs, "ref must have only one of tag or commit")
+	case commit != "" && !shaRe.MatchString(commit):
+		errs = append(errs, fmt.Sprintf("ref.commit %q is not a 40 character sha", commit))
+	}
+
+	if c.GithubOrg == "" {
+		errs = append(errs, "github_org is missing")
+	}
+	if c.RepoName == "" {
+		errs = append(errs, "repo_name is missing")
+	}
+
+	if len(errs) != 0 {
+		return c, errors.New("invalid Gutenberg config: " + strings.Join(errs, ", "))
+	}
+	return c, nil
+}
+
+// RefName returns the tag or the commit the config points to
+func (c GutenbergConfig) RefName() string {
+	if c.Ref.Tag != "" {
+		return c.Ref.Tag
+	}
+	return c.Ref.Commit
+}
+
+// VerifyRef checks that the tag or commit in the config exists on GitHub
+func (c GutenbergConfig) VerifyRef() error {
+	if c.Ref.Tag != "" {
+		if _, err := gh.GetRefOrg(c.GithubOrg, c.RepoName, "tags/"+c.Ref.Tag); err != nil {
+			if gh.IsNotFound(err) {
+				return fmt.Errorf("tag %s does not exist on %s/%s", c.Ref.Tag, c.GithubOrg, c.RepoName)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if _, err := gh.GetCommitOrg(c.GithubOrg, c.RepoName, c.Ref.Commit); err != nil {
+		if gh.IsNotFound(err) {
+			return fmt.Errorf("commit %s does not exist on %s/%s", c.Ref.Commit, c.GithubOrg, c.RepoName)
+		}
+		return err
+	}
+	return nil
+}
+
+func previewConfigDiff(path, before, after string) {
+	console.Print(console.Heading, "\nChanges to %s", path)
+	red := color.New(color.FgRed)
+	for _, l := range strings.Split(diff.Diff(before, after), "\n") {
+		switch {
+		case strings.HasPrefix(l, "+"):
+			console.Print(console.Row, l)
+		case strings.HasPrefix(l, "-"):
+			console.Print(red, l)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package integrate
+
+import "testing"
+
+func TestParseGutenbergConfig(t *testing.T) {
+	valid := func(ref string) string {
+		return "ref:\n" + ref + "github_org: wordpress-mobile\nrepo_name: gutenberg-mobile\n"
+	}
+
+	t.Run("It accepts a tag", func(t *testing.T) {
+		c, err := ParseGutenbergConfig(valid("  tag: v1.109.0\n"))
+		assertNoError(t, err)
+		if c.Ref.Tag != "v1.109.0" {
+			t.Fatalf("Expected tag v1.109.0, got %s", c.Ref.Tag)
+		}
+		if c.RefName() != "v1.109.0" {
+			t.Fatalf("Expected the tag as the ref name, got %s", c.RefName())
+		}
+	})
+
+	t.Run("It accepts a commit sha", func(t *testing.T) {
+		c, err := ParseGutenbergConfig(valid("  commit: 0123456789abcdef0123456789abcdef01234567\n"))
+		assertNoError(t, err)
+		if c.RefName() != "0123456789abcdef0123456789abcdef01234567" {
+			t.Fatalf("Expected the commit as the ref name, got %s", c.RefName())
+		}
+	})
+
+	t.Run("It returns an error if both tag and commit are set", func(t *testing.T) {
+		_, err := ParseGutenbergConfig(valid("  tag: v1.109.0\n  commit: 0123456789abcdef0123456789abcdef01234567\n"))
+		assertError(t, err)
+	})
+
+	t.Run("It returns an error if neither tag nor commit are set", func(t *testing.T) {
+		_, err := ParseGutenbergConfig(valid("  other: value\n"))
+		assertError(t, err)
+	})
+
+	t.Run("It returns an error if the commit is not a full sha", func(t *testing.T) {
+		_, err := ParseGutenbergConfig(valid("  commit: 0123456\n"))
+		assertError(t, err)
+	})
+
+	t.Run("It returns an error if the github org is missing", func(t *testing.T) {
+		_, err := ParseGutenbergConfig("ref:\n  tag: v1.109.0\nrepo_name: gutenberg-mobile\n")
+		assertError(t, err)
+	})
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
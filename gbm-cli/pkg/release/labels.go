@@ -0,0 +1,131 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+// labelsByRepo names the labels the release PRs of each repo are tagged with
+var labelsByRepo = map[string][]string{
+	repo.GutenbergRepo:        {GbReleasePrLabel, GbBuildToolingLabel},
+	repo.GutenbergMobileRepo:  {GbmReleasePrLabel},
+	repo.WordPressAndroidRepo: {IntegratePrLabel},
+	repo.WordPressIosRepo:     {IntegratePrLabel},
+}
+
+// LabelRepos are the repos the release PRs are opened on
+var LabelRepos = labelRepos()
+
+func labelRepos() []string {
+	rpos := []string{}
+	for rpo := range labelsByRepo {
+		rpos = append(rpos, rpo)
+	}
+	sort.Strings(rpos)
+	return rpos
+}
+
+// LabelsFor returns the names of the labels the release PRs of the repo are tagged with
+func LabelsFor(rpo string) []string {
+	return labelsByRepo[rpo]
+}
+
+// UpstreamLabels returns the labels of the release PRs of the repo as they are on the upstream repos,
+// so setup-labels copies their colors and never recolors them upstream.
+// A label missing on the upstream repo is copied from the other upstream repos it's used on.
+func UpstreamLabels(rpo string) ([]gh.Label, error) {
+	labels := []gh.Label{}
+	for _, name := range labelsByRepo[rpo] {
+		label, err := upstreamLabel(rpo, name)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+func upstreamLabel(rpo, name string) (gh.Label, error) {
+	for _, r := range append([]string{rpo}, LabelRepos...) {
+		if !containsLabel(labelsByRepo[r], name) {
+			continue
+		}
+		org := repo.UpstreamOrg(r)
+		label, err := gh.GetLabelOrg(org, r, name)
+		if gh.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return label, fmt.Errorf("error getting the %q label on %s/%s: %v", name, org, r, err)
+		}
+		return label, nil
+	}
+	// Nowhere upstream to copy it from, GitHub picks the color
+	return gh.Label{Name: name}, nil
+}
+
+func containsLabel(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelChange is a label to create, or to update when Existing is set
+type LabelChange struct {
+	Repo     string
+	Label    gh.Label
+	Existing *gh.Label
+}
+
+func (c LabelChange) String() string {
+	if c.Existing == nil && c.Label.Color == "" {
+		return fmt.Sprintf("Create %q on %s/%s", c.Label.Name, repo.GetOrg(c.Repo), c.Repo)
+	}
+	if c.Existing == nil {
+		return fmt.Sprintf("Create %q (#%s) on %s/%s", c.Label.Name, c.Label.Color, repo.GetOrg(c.Repo), c.Repo)
+	}
+	return fmt.Sprintf("Update %q from #%s to #%s on %s/%s", c.Existing.Name, c.Existing.Color, c.Label.Color, repo.GetOrg(c.Repo), c.Repo)
+}
+
+// PlanLabels returns the changes needed for the repo to have the wanted labels.
+// Label names are case insensitive on GitHub, so a label only differing by case is updated.
+// Existing descriptions are kept, and so are the colors when the wanted label has none.
+func PlanLabels(rpo string, existing, wanted []gh.Label) []LabelChange {
+	changes := []LabelChange{}
+	for _, w := range wanted {
+		var found *gh.Label
+		for i := range existing {
+			if strings.EqualFold(existing[i].Name, w.Name) {
+				found = &existing[i]
+				break
+			}
+		}
+		if found == nil {
+			changes = append(changes, LabelChange{Repo: rpo, Label: w})
+			continue
+		}
+		if found.Name != w.Name || (w.Color != "" && !strings.EqualFold(found.Color, w.Color)) {
+			w.Description = ""
+			if w.Color == "" {
+				w.Color = found.Color
+			}
+			changes = append(changes, LabelChange{Repo: rpo, Label: w, Existing: found})
+		}
+	}
+	return changes
+}
+
+// ApplyLabelChange creates or updates the label
+func ApplyLabelChange(c LabelChange) error {
+	if c.Existing == nil {
+		return gh.CreateLabel(c.Repo, c.Label)
+	}
+	return gh.UpdateLabel(c.Repo, c.Existing.Name, c.Label)
+}
@@ -0,0 +1,101 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+func TestPlanLabels(t *testing.T) {
+	wanted := []gh.Label{
+		{Name: "release-process", Color: "1d76db"},
+		{Name: "Gutenberg", Color: "0e8a16"},
+	}
+
+	t.Run("It creates the missing labels", func(t *testing.T) {
+		changes := PlanLabels("gutenberg-mobile", []gh.Label{{Name: "bug", Color: "d73a4a"}}, wanted)
+
+		if len(changes) != 2 {
+			t.Fatalf("Expected 2 changes, got %v", changes)
+		}
+		assertEqual(t, changes[0].String(), `Create "release-process" (#1d76db) on wordpress-mobile/gutenberg-mobile`)
+	})
+
+	t.Run("It updates labels with another color or case", func(t *testing.T) {
+		existing := []gh.Label{
+			{Name: "Release-Process", Color: "1D76DB", Description: "Ours"},
+			{Name: "Gutenberg", Color: "ffffff"},
+		}
+		changes := PlanLabels("gutenberg-mobile", existing, wanted)
+
+		if len(changes) != 2 {
+			t.Fatalf("Expected 2 changes, got %v", changes)
+		}
+		assertEqual(t, changes[0].Existing.Name, "Release-Process")
+		assertEqual(t, changes[0].Label.Name, "release-process")
+		assertEqual(t, changes[1].String(), `Update "Gutenberg" from #ffffff to #0e8a16 on wordpress-mobile/gutenberg-mobile`)
+	})
+
+	t.Run("It leaves matching labels alone", func(t *testing.T) {
+		existing := []gh.Label{
+			{Name: "release-process", Color: "1D76DB"},
+			{Name: "Gutenberg", Color: "0e8a16"},
+		}
+		if changes := PlanLabels("gutenberg-mobile", existing, wanted); len(changes) != 0 {
+			t.Fatalf("Expected no changes, got %v", changes)
+		}
+	})
+
+	t.Run("It returns the labels of the release PRs of each repo", func(t *testing.T) {
+		labels := LabelsFor("gutenberg")
+		if len(labels) != 2 {
+			t.Fatalf("Expected 2 labels, got %v", labels)
+		}
+		assertEqual(t, labels[0], GbReleasePrLabel)
+		assertEqual(t, labels[1], GbBuildToolingLabel)
+		if len(LabelRepos) != 4 {
+			t.Fatalf("Expected the 4 repos with labels, got %v", LabelRepos)
+		}
+	})
+
+	t.Run("It keeps the color of existing labels when the wanted one has none", func(t *testing.T) {
+		existing := []gh.Label{{Name: "gutenberg", Color: "ffffff"}}
+		changes := PlanLabels("WordPress-iOS", existing, []gh.Label{{Name: "Gutenberg"}})
+
+		if len(changes) != 1 {
+			t.Fatalf("Expected 1 change, got %v", changes)
+		}
+		assertEqual(t, changes[0].Label.Color, "ffffff")
+	})
+}
+
+func TestUpstreamLabels(t *testing.T) {
+
+	t.Run("It copies the labels of the upstream repos", func(t *testing.T) {
+		t.Cleanup(repo.InitOrgs)
+		t.Setenv("GBM_WORDPRESS_ORG", "octocat")
+		t.Setenv("GBM_WPMOBILE_ORG", "octocat")
+		repo.InitOrgs()
+
+		fakeGitHub(t).
+			On("GET repos/WordPress/gutenberg/labels/Mobile App", 200, gh.Label{Name: GbReleasePrLabel, Color: "a3ef7a"}).
+			On("GET repos/wordpress-mobile/WordPress-Android/labels/Gutenberg", 200, gh.Label{Name: IntegratePrLabel, Color: "0e8a16"})
+
+		labels, err := UpstreamLabels("gutenberg")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertEqual(t, labels[0].Color, "a3ef7a")
+		// Nowhere upstream, GitHub picks the color
+		assertEqual(t, labels[1].Name, GbBuildToolingLabel)
+		assertEqual(t, labels[1].Color, "")
+
+		// Missing on WordPress-iOS, copied from WordPress-Android
+		labels, err = UpstreamLabels("WordPress-iOS")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertEqual(t, labels[0].Color, "0e8a16")
+	})
+}
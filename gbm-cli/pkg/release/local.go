@@ -0,0 +1,92 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/mirror"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+// checkoutBranch sets up the release branch from the build base.
+// The repo is cloned into the build directory, unless the build is Local in which case
+// the branch is created in the developer's checkout.
+func checkoutBranch(build Build, rpo, branch string, recursive bool, git shell.GitCmds) error {
+	if build.Local {
+		return checkoutLocalBranch(build, rpo, branch, recursive, git)
+	}
+
+	// Cloning into a non empty directory fails, e.g. in CI where the workspace is the current directory
+	if entries, err := os.ReadDir(build.Dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s is not empty, use --local %s=%s to release from an existing checkout", build.Dir, rpo, build.Dir)
+	}
+
+	console.Info("Cloning %s to %s", rpo, build.Dir)
+	if err := mirror.Clone(rpo, build.Base.Ref, build.shellProps(build.Dir), recursive); err != nil {
+		return err
+	}
+
+	console.Info("Checking out branch %s", branch)
+	return git.Switch("-c", branch)
+}
+
+func checkoutLocalBranch(build Build, rpo, branch string, recursive bool, git shell.GitCmds) error {
+	console.Info("Using the local %s checkout in %s", rpo, build.Dir)
+	if err := VerifyLocalCheckout(rpo, git); err != nil {
+		return err
+	}
+
+	// Branch off the up to date base, whatever is checked out.
+	// Fetching the ref by name leaves the fetch config of the developer's checkout alone, unlike Fetch.
+	if err := git.FetchRemote("origin", build.Base.Ref); err != nil {
+		return fmt.Errorf("error fetching %s: %v", build.Base.Ref, err)
+	}
+	base, err := git.RevParse("FETCH_HEAD")
+	if err != nil {
+		return err
+	}
+
+	console.Info("Checking out branch %s from %s (%s)", branch, build.Base.Ref, base[:min(len(base), 7)])
+	if err := git.Switch("-c", branch, base); err != nil {
+		return err
+	}
+	if recursive {
+		return git.Submodule("update", "--init", "--recursive")
+	}
+	return nil
+}
+
+// VerifyLocalCheckout checks that the checkout has no changes and that origin is the upstream repo,
+// since the release branch is pushed to origin
+func VerifyLocalCheckout(rpo string, git shell.GitCmds) error {
+	status, err := git.Status()
+	if err != nil {
+		return fmt.Errorf("error reading the status of the checkout: %v", err)
+	}
+	if !status.Clean() {
+		files := []string{}
+		for _, f := range status.Files {
+			files = append(files, f.Path)
+		}
+		return fmt.Errorf("the %s checkout has changes, commit or stash them first: %s", rpo, strings.Join(files, ", "))
+	}
+
+	url, err := git.RemoteURL("origin")
+	if err != nil {
+		return fmt.Errorf("error reading the origin of the %s checkout: %v", rpo, err)
+	}
+	if !isRepoUrl(url, repo.GetOrg(rpo), rpo) {
+		return fmt.Errorf("the origin of the %s checkout is %s, expected %s/%s", rpo, url, repo.GetOrg(rpo), rpo)
+	}
+	return nil
+}
+
+// Matches https and ssh urls, e.g. git@github.com:WordPress/gutenberg.git
+func isRepoUrl(url, org, rpo string) bool {
+	path := strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git"))
+	want := strings.ToLower(org + "/" + rpo)
+	return strings.HasSuffix(path, "github.com/"+want) || strings.HasSuffix(path, "github.com:"+want)
+}
@@ -0,0 +1,96 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+func TestVerifyLocalCheckout(t *testing.T) {
+	origin := "git@github.com:" + repo.GetOrg("gutenberg") + "/gutenberg.git"
+
+	t.Run("It accepts a clean checkout of the upstream repo", func(t *testing.T) {
+		fake := shell.NewFake().On("git remote get-url origin", origin+"\n", 0)
+		git := shell.NewGitCmd(shell.CmdProps{Dir: "/gb", Backend: fake})
+
+		if err := VerifyLocalCheckout("gutenberg", git); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("It rejects a checkout with changes", func(t *testing.T) {
+		fake := shell.NewFake().
+			On("git remote get-url origin", origin+"\n", 0).
+			On("git status", "? notes.txt\x00", 0)
+		git := shell.NewGitCmd(shell.CmdProps{Dir: "/gb", Backend: fake})
+
+		err := VerifyLocalCheckout("gutenberg", git)
+		if err == nil || !strings.Contains(err.Error(), "notes.txt") {
+			t.Fatalf("Expected an error listing the changes, got %v", err)
+		}
+	})
+
+	t.Run("It rejects a checkout of another repo", func(t *testing.T) {
+		fake := shell.NewFake().On("git remote get-url origin", "https://github.com/octocat/gutenberg\n", 0)
+		git := shell.NewGitCmd(shell.CmdProps{Dir: "/gb", Backend: fake})
+
+		if err := VerifyLocalCheckout("gutenberg", git); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+}
+
+func TestCheckoutBranch(t *testing.T) {
+
+	t.Run("It creates the branch from the base in a local checkout", func(t *testing.T) {
+		fake := shell.NewFake().
+			On("git remote get-url origin", "https://github.com/"+repo.GetOrg("gutenberg-mobile")+"/gutenberg-mobile.git\n", 0).
+			On("git rev-parse", "1234567890abcdef\n", 0)
+		build := Build{Dir: "/gbm", Local: true, Base: gh.Repo{Ref: "trunk"}, Shell: fake}
+		git := shell.NewGitCmd(build.shellProps(build.Dir))
+
+		if err := checkoutBranch(build, "gutenberg-mobile", "release/1.109.0", true, git); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got := fake.Commands()[len(fake.Commands())-2:]
+		want := []string{
+			"git switch -c release/1.109.0 1234567890abcdef",
+			"git submodule update --init --recursive",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", got, want)
+		}
+		fetched := false
+		for _, c := range fake.Commands() {
+			if strings.HasPrefix(c, "git clone") || strings.HasPrefix(c, "git remote set-branches") {
+				t.Fatalf("Expected no clone nor changes to the remote config, got %v", fake.Commands())
+			}
+			fetched = fetched || c == "git fetch origin trunk"
+		}
+		if !fetched {
+			t.Fatalf("Expected the base to be fetched by name, got %v", fake.Commands())
+		}
+	})
+
+	t.Run("It does not clone into a directory with files", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("toolkit"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fake := shell.NewFake()
+		build := Build{Dir: dir, Base: gh.Repo{Ref: "trunk"}, Shell: fake}
+		git := shell.NewGitCmd(build.shellProps(build.Dir))
+
+		if err := checkoutBranch(build, "gutenberg", "rnmobile/release_1.109.0", false, git); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		assertEqual(t, strings.Join(fake.Commands(), ""), "")
+	})
+}
@@ -3,6 +3,7 @@ package release
 import (
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/semver"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
 )
 
 type Build struct {
@@ -13,6 +14,17 @@ type Build struct {
 	Prs         []gh.PullRequest
 	Base        gh.Repo
 	Depth       string
+
+	// Local builds run in the developer's checkout in Dir instead of a fresh clone
+	Local bool
+
+	// Shell runs the git, npm and bundler commands. Defaults to executing them.
+	Shell shell.Backend
+}
+
+// Returns the props for the shell clients of the build running in dir
+func (b Build) shellProps(dir string) shell.CmdProps {
+	return shell.CmdProps{Dir: dir, Verbose: true, Backend: b.Shell}
 }
 
 type ReleaseChanges struct {
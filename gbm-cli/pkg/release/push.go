@@ -0,0 +1,76 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+// PushBranch pushes the current branch to origin, or the fork when repo.ForkOwner is set, and tracks it.
+// When rerunning on a branch that already exists and diverged, it asks before overwriting it, --yes and CI don't answer that.
+// The push is leased against the sha seen here, so commits pushed meanwhile are never lost.
+func PushBranch(rpo string, git shell.GitCmds) error {
+	branch, err := git.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	remote := "origin"
+	if repo.ForkOwner != "" {
+		remote = repo.ForkRemote
+		if err := addForkRemote(rpo, git); err != nil {
+			return err
+		}
+	} else if b, err := gh.SearchBranch(rpo, branch); err == nil && b.Protected {
+		return fmt.Errorf("refusing to push to %s, the branch is protected on %s/%s", branch, repo.GetOrg(rpo), rpo)
+	}
+
+	remoteSha, err := git.RemoteSha(remote, branch)
+	if err != nil {
+		return fmt.Errorf("error checking the remote branch %s: %v", branch, err)
+	}
+
+	opts := shell.PushOptions{Remote: remote, Branch: branch, SetUpstream: true}
+	if remoteSha != "" {
+		// Fetch errors just mean we can't tell, which is treated as diverged
+		if remote == "origin" {
+			git.Fetch(branch)
+		} else {
+			git.FetchRemote(remote, branch)
+		}
+		if ok, _ := git.IsAncestor(remoteSha, "HEAD"); !ok {
+			console.Warn("The branch %s already exists on %s and has diverged", branch, rpo)
+			if !console.ConfirmExplicit("overwrite_remote_branch", "Overwrite the remote branch?") {
+				return fmt.Errorf("the remote branch %s has diverged", branch)
+			}
+		}
+		opts.ForceWithLease = true
+		opts.ExpectedSha = remoteSha
+	}
+	return git.PushWith(opts)
+}
+
+// BranchExists returns whether the branch exists where PushBranch pushes it, on the fork when repo.ForkOwner is set
+func BranchExists(rpo, branch string) (bool, error) {
+	return refExistsOrg(pushOrg(rpo), rpo, "heads/"+branch)
+}
+
+// Returns the org PushBranch pushes to, the ForkOwner when using forks
+func pushOrg(rpo string) string {
+	if repo.ForkOwner != "" {
+		return repo.ForkOwner
+	}
+	return repo.GetOrg(rpo)
+}
+
+func addForkRemote(rpo string, git shell.GitCmds) error {
+	exists, err := git.HasRemote(repo.ForkRemote)
+	if err != nil || exists {
+		return err
+	}
+	console.Info("Adding the %s/%s fork as the %s remote", repo.ForkOwner, rpo, repo.ForkRemote)
+	return git.AddRemote(repo.ForkRemote, repo.GetForkRepoHttpsPath(rpo))
+}
@@ -0,0 +1,19 @@
+package release
+
+import (
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/report"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
+)
+
+// RecordBase adds the ref the release branch was created from, and its sha, to the run report
+func RecordBase(rpo, ref string, git shell.GitCmds) {
+	sha, _ := git.HeadSha()
+	report.RecordBase(rpo, ref, sha)
+}
+
+// RecordHead adds the pushed release branch, and its sha, to the run report
+func RecordHead(rpo string, git shell.GitCmds) {
+	branch, _ := git.CurrentBranch()
+	sha, _ := git.HeadSha()
+	report.RecordHead(rpo, branch, sha)
+}
@@ -0,0 +1,249 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+	"gopkg.in/yaml.v3"
+)
+
+// ReviewerRule is who reviews the release PRs of a repo
+type ReviewerRule struct {
+	Users []string `yaml:"users"`
+	// Teams are team slugs, e.g. mobile-team or wordpress-mobile/mobile-team
+	Teams []string `yaml:"teams"`
+	// CodeOwners also requests reviews from the owners of the changed files in the repo's CODEOWNERS
+	CodeOwners bool `yaml:"codeowners"`
+}
+
+// ReviewerRules are the reviewer rules by repo, loaded with LoadReviewerRules
+var ReviewerRules = map[string]ReviewerRule{}
+
+// ReleaseManager is assigned to the release PRs. Defaults to the user of the GitHub token.
+var ReleaseManager string
+
+// Where GitHub looks for the CODEOWNERS file, in order
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// LoadReviewerRules reads the reviewer rules from a YAML file mapping repos to rules, e.g.
+//
+//	gutenberg:
+//	  teams: [mobile-team]
+//	  codeowners: true
+func LoadReviewerRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rules := map[string]ReviewerRule{}
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("error parsing the reviewers file %s: %v", path, err)
+	}
+	ReviewerRules = rules
+	return nil
+}
+
+// AssignAndRequestReviews assigns the release manager to the PR and requests reviews following the repo's rule.
+// Failures are only warned about since the PR is already created.
+func AssignAndRequestReviews(rpo string, pr *gh.PullRequest) {
+	manager := ReleaseManager
+	if manager == "" {
+		if user, err := gh.CurrentUser(); err != nil {
+			console.Warn("Unable to get the release manager to assign: %v", err)
+		} else {
+			manager = user.Login
+		}
+	}
+	if manager != "" {
+		if err := gh.AddAssignees(rpo, pr.Number, []string{manager}); err != nil {
+			console.Warn("Unable to assign %s to %s: %v", manager, pr.Url, err)
+		} else {
+			console.Info("Assigned %s to the PR", manager)
+		}
+	}
+
+	rule, ok := ReviewerRules[rpo]
+	if !ok {
+		return
+	}
+	// Copied so appending the code owners never writes into the shared rule
+	users := append([]string{}, rule.Users...)
+	teams := append([]string{}, rule.Teams...)
+	if rule.CodeOwners {
+		owners, ownerTeams, err := prCodeOwners(rpo, *pr)
+		if err != nil {
+			console.Warn("Unable to find the code owners of %s: %v", pr.Url, err)
+		}
+		users = append(users, owners...)
+		teams = append(teams, ownerTeams...)
+	}
+
+	// GitHub refuses review requests from the PR author
+	users = without(unique(users), pr.User.Login)
+	teams = unique(teamSlugs(rpo, teams))
+	if len(users) == 0 && len(teams) == 0 {
+		return
+	}
+	if err := gh.RequestReviewers(rpo, pr, users, teams); err != nil {
+		console.Warn("Unable to request reviews on %s: %v", pr.Url, err)
+		return
+	}
+	console.Info("Requested reviews from %s", strings.Join(append(users, teams...), ", "))
+}
+
+func prCodeOwners(rpo string, pr gh.PullRequest) (users, teams []string, err error) {
+	codeOwners := ""
+	for _, path := range codeOwnersPaths {
+		if codeOwners, err = gh.GetFileContent(rpo, pr.Base.Ref, path); err == nil {
+			break
+		}
+		if !gh.IsNotFound(err) {
+			return nil, nil, err
+		}
+	}
+	if codeOwners == "" {
+		return nil, nil, nil
+	}
+
+	files, err := gh.GetPrFiles(rpo, pr.Number)
+	if err != nil {
+		return nil, nil, err
+	}
+	users, teams = CodeOwners(codeOwners, files)
+	return users, teams, nil
+}
+
+// CodeOwners returns the users and teams owning the files in a CODEOWNERS file.
+// As on GitHub, the last matching pattern of a file takes precedence.
+func CodeOwners(codeOwners string, files []string) (users, teams []string) {
+	type rule struct {
+		re     *regexp.Regexp
+		owners []string
+	}
+	rules := []rule{}
+	for _, line := range strings.Split(codeOwners, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		owners := []string{}
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "#") {
+				break
+			}
+			owners = append(owners, f)
+		}
+		rules = append(rules, rule{re: codeOwnersRegexp(fields[0]), owners: owners})
+	}
+
+	for _, file := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if !rules[i].re.MatchString(file) {
+				continue
+			}
+			for _, o := range rules[i].owners {
+				// Owners can also be emails, which can't be requested by the api
+				if !strings.HasPrefix(o, "@") {
+					continue
+				}
+				if o = strings.TrimPrefix(o, "@"); strings.Contains(o, "/") {
+					teams = append(teams, o)
+				} else {
+					users = append(users, o)
+				}
+			}
+			break
+		}
+	}
+	return unique(users), unique(teams)
+}
+
+// codeOwnersRegexp converts a CODEOWNERS pattern, which follows the gitignore rules, to a regexp.
+// Patterns with a leading or middle slash are relative to the root, the others match at any depth.
+// A pattern matching a directory matches everything in it.
+func codeOwnersRegexp(pattern string) *regexp.Regexp {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.Trim(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+	// A last segment with a wildcard, e.g. docs/*, only matches the files directly in the directory
+	segments := strings.Split(p, "/")
+	last := segments[len(segments)-1]
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.Contains(last, "*") && !strings.Contains(last, "**"):
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.MustCompile(b.String())
+}
+
+// teamSlugs strips the @ and org of the teams, the api takes the slugs of the repo org's teams.
+// Teams of other orgs can't be requested, they would resolve to a same named team of the repo's org.
+func teamSlugs(rpo string, teams []string) []string {
+	org := repo.GetOrg(rpo)
+	slugs := []string{}
+	for _, t := range teams {
+		t = strings.TrimPrefix(t, "@")
+		if i := strings.LastIndex(t, "/"); i >= 0 {
+			if !strings.EqualFold(t[:i], org) {
+				console.Warn("Not requesting a review from %s, it's not a team of %s", t, org)
+				continue
+			}
+			t = t[i+1:]
+		}
+		slugs = append(slugs, t)
+	}
+	return slugs
+}
+
+func unique(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func without(values []string, value string) []string {
+	result := []string{}
+	for _, v := range values {
+		if !strings.EqualFold(v, value) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
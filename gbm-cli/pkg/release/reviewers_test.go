@@ -0,0 +1,115 @@
+package release
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+func TestCodeOwners(t *testing.T) {
+	codeOwners := `
+# Default owners
+*                                @wordpress-mobile/mobile-team
+
+/packages/react-native-editor/   @alice @WordPress/native-mobile
+/docs/*                          @erin
+*.md                             @bob docs@example.com
+packages/**/ios/                 @carol # iOS folks
+/ios-xcframework                 @dave
+`
+
+	tests := []struct {
+		name  string
+		file  string
+		users []string
+		teams []string
+	}{
+		{"It falls back to the default owners", "package.json", []string{}, []string{"wordpress-mobile/mobile-team"}},
+		{"It matches anchored directories", "packages/react-native-editor/src/index.js", []string{"alice"}, []string{"WordPress/native-mobile"}},
+		{"It matches extensions at any depth and skips emails", "packages/react-native-editor/CHANGELOG.md", []string{"bob"}, []string{}},
+		{"It matches ** across directories", "packages/react-native-bridge/ios/Gutenberg.swift", []string{"carol"}, []string{}},
+		{"It matches a directory without a trailing slash", "ios-xcframework/Gemfile", []string{"dave"}, []string{}},
+		{"It matches the files directly in a directory with a wildcard", "docs/setup.txt", []string{"erin"}, []string{}},
+		{"It doesn't match the nested files with a wildcard", "docs/guides/setup.txt", []string{}, []string{"wordpress-mobile/mobile-team"}},
+		{"It doesn't match anchored patterns deeper", "packages/ios-xcframework/Gemfile", []string{}, []string{"wordpress-mobile/mobile-team"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, teams := CodeOwners(codeOwners, []string{tt.file})
+			if !reflect.DeepEqual(users, tt.users) || !reflect.DeepEqual(teams, tt.teams) {
+				t.Fatalf("got %v %v, want %v %v", users, teams, tt.users, tt.teams)
+			}
+		})
+	}
+
+	t.Run("It merges the owners of all the files", func(t *testing.T) {
+		users, teams := CodeOwners(codeOwners, []string{"README.md", "docs/README.md", "package.json"})
+		assertEqual(t, strings.Join(users, ","), "bob")
+		assertEqual(t, strings.Join(teams, ","), "wordpress-mobile/mobile-team")
+	})
+}
+
+func TestReviewerRules(t *testing.T) {
+	t.Cleanup(func() { ReviewerRules = map[string]ReviewerRule{} })
+
+	t.Run("It loads the rules by repo", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "reviewers.yml")
+		yml := "gutenberg:\n  users: [alice]\n  teams: [WordPress/native-mobile]\n  codeowners: true\nWordPress-iOS:\n  teams: [ios]\n"
+		if err := os.WriteFile(path, []byte(yml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := LoadReviewerRules(path); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		rule := ReviewerRules["gutenberg"]
+		if !rule.CodeOwners || !reflect.DeepEqual(rule.Users, []string{"alice"}) {
+			t.Fatalf("got %+v", rule)
+		}
+		assertEqual(t, strings.Join(ReviewerRules["WordPress-iOS"].Teams, ","), "ios")
+	})
+
+	t.Run("It requests reviews from the team slugs without the author", func(t *testing.T) {
+		slugs := teamSlugs("gutenberg", []string{"@" + repo.GetOrg("gutenberg") + "/native-mobile", "ios"})
+		assertEqual(t, strings.Join(slugs, ","), "native-mobile,ios")
+		assertEqual(t, strings.Join(without([]string{"alice", "Bob"}, "bob"), ","), "alice")
+	})
+
+	t.Run("It drops the teams of other orgs", func(t *testing.T) {
+		slugs := teamSlugs("gutenberg", []string{"@octocat/mobile-team", "@" + repo.GetOrg("gutenberg") + "/native-mobile"})
+		assertEqual(t, strings.Join(slugs, ","), "native-mobile")
+	})
+}
+
+func TestAssignAndRequestReviews(t *testing.T) {
+
+	t.Run("It adds the code owners without changing the rule", func(t *testing.T) {
+		t.Cleanup(func() { ReviewerRules = map[string]ReviewerRule{} })
+		users := make([]string, 1, 4)
+		users[0] = "alice"
+		ReviewerRules = map[string]ReviewerRule{"gutenberg": {Users: users, CodeOwners: true}}
+
+		gb := "repos/" + repo.GetOrg("gutenberg") + "/gutenberg"
+		codeOwners := base64.StdEncoding.EncodeToString([]byte("/packages/ @bob\n"))
+		api := fakeGitHub(t).
+			On("GET user", 200, gh.User{Login: "manager"}).
+			On("POST "+gb+"/issues/7/assignees", 201, "{}").
+			On("GET "+gb+"/contents/.github/CODEOWNERS", 200, map[string]string{"content": codeOwners, "encoding": "base64"}).
+			On("GET "+gb+"/pulls/7/files", 200, []map[string]string{{"filename": "packages/editor/index.js"}}).
+			On("POST "+gb+"/pulls/7/requested_reviewers", 201, gh.PullRequest{Number: 7})
+
+		pr := gh.PullRequest{Number: 7, Base: gh.Repo{Ref: "trunk"}}
+		AssignAndRequestReviews("gutenberg", &pr)
+
+		if !api.Sent("POST " + gb + "/pulls/7/requested_reviewers") {
+			t.Fatalf("Expected the reviews to be requested, got %v", api.Requests())
+		}
+		assertEqual(t, strings.Join(users[:cap(users)], ","), "alice,,,")
+	})
+}
@@ -0,0 +1,148 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+// Kinds of artifacts, in the order they are rolled back.
+// PRs are closed before their branches are deleted so they are closed rather than orphaned.
+const (
+	ArtifactPr     = "pr"
+	ArtifactBranch = "branch"
+	ArtifactTag    = "tag"
+)
+
+// Artifact is a PR, branch or tag created on GitHub by preparing or integrating a release
+type Artifact struct {
+	// Org is where the branch or tag lives, the fork owner for release branches pushed to a fork
+	Org  string
+	Repo string
+	Kind string
+	Name string
+	Url  string
+	Pr   gh.PullRequest
+
+	// Blocked is why the artifact can't be rolled back, e.g. PRs based on an after branch
+	Blocked string
+}
+
+func (a Artifact) String() string {
+	return fmt.Sprintf("%s %s on %s/%s", a.Kind, a.Name, a.org(), a.Repo)
+}
+
+func (a Artifact) org() string {
+	if a.Org != "" {
+		return a.Org
+	}
+	return repo.GetOrg(a.Repo)
+}
+
+// FindArtifacts returns the open PRs, branches and tags created for the release version across the repos
+func FindArtifacts(version string) ([]Artifact, error) {
+	artifacts := []Artifact{}
+
+	// The branches and tags of repos whose release PR was merged shipped with the release
+	merged := map[string]bool{}
+	for _, rpo := range []string{repo.GutenbergRepo, repo.GutenbergMobileRepo, repo.WordPressAndroidRepo, repo.WordPressIosRepo} {
+		// An aborted and retried release leaves closed PRs beside the open one, only the open ones are rolled back
+		open, err := gh.SearchPrs(releasePrFilter(rpo, version, "is:open"))
+		if err != nil {
+			return nil, fmt.Errorf("error searching the open release PRs on %s: %v", rpo, err)
+		}
+		for _, pr := range open.Items {
+			artifacts = append(artifacts, Artifact{Repo: rpo, Kind: ArtifactPr, Name: fmt.Sprintf("#%d %s", pr.Number, pr.Title), Url: pr.Url, Pr: pr})
+		}
+
+		done, err := gh.SearchPrs(releasePrFilter(rpo, version, "is:merged"))
+		if err != nil {
+			return nil, fmt.Errorf("error searching the merged release PRs on %s: %v", rpo, err)
+		}
+		for _, pr := range done.Items {
+			merged[rpo] = true
+			console.Warn("The release PR %s is merged, it can't be rolled back", pr.Url)
+		}
+	}
+
+	// The release branches are pushed to the fork when using one, the after branches and tags stay upstream
+	refs := []struct {
+		org, rpo, kind, name string
+	}{
+		{pushOrg(repo.GutenbergRepo), repo.GutenbergRepo, ArtifactBranch, "rnmobile/release_" + version},
+		{pushOrg(repo.GutenbergMobileRepo), repo.GutenbergMobileRepo, ArtifactBranch, "release/" + version},
+		{pushOrg(repo.WordPressAndroidRepo), repo.WordPressAndroidRepo, ArtifactBranch, fmt.Sprintf(IntegrateBranchName, version)},
+		{pushOrg(repo.WordPressIosRepo), repo.WordPressIosRepo, ArtifactBranch, fmt.Sprintf(IntegrateBranchName, version)},
+		{repo.GetOrg(repo.WordPressAndroidRepo), repo.WordPressAndroidRepo, ArtifactBranch, AfterBranchName(version)},
+		{repo.GetOrg(repo.WordPressIosRepo), repo.WordPressIosRepo, ArtifactBranch, AfterBranchName(version)},
+		{repo.GetOrg(repo.GutenbergRepo), repo.GutenbergRepo, ArtifactTag, "rnmobile/" + version},
+	}
+	for _, r := range refs {
+		exists, err := refExistsOrg(r.org, r.rpo, refPath(r.kind, r.name))
+		if err != nil {
+			return nil, fmt.Errorf("error looking up the %s %s on %s/%s: %v", r.kind, r.name, r.org, r.rpo, err)
+		}
+		if !exists {
+			continue
+		}
+		a := Artifact{Org: r.org, Repo: r.rpo, Kind: r.kind, Name: r.name}
+		if merged[r.rpo] {
+			a.Blocked = "the release PR was merged"
+		} else if r.name == AfterBranchName(version) {
+			// Deleting the after branch would close the PRs waiting on the release
+			based, err := gh.ListPrsByBase(r.rpo, r.name)
+			if err != nil {
+				return nil, fmt.Errorf("error listing the PRs based on %s: %v", r.name, err)
+			}
+			if len(based) > 0 {
+				a.Blocked = fmt.Sprintf("%d open PRs are based on it", len(based))
+			}
+		}
+		artifacts = append(artifacts, a)
+	}
+
+	return artifacts, nil
+}
+
+// RollbackArtifact closes the PR or deletes the branch or tag
+func RollbackArtifact(a Artifact) error {
+	if a.Blocked != "" {
+		return fmt.Errorf("not rolling back the %s, %s", a, a.Blocked)
+	}
+	switch a.Kind {
+	case ArtifactPr:
+		return gh.ClosePr(a.Repo, &a.Pr)
+	case ArtifactBranch, ArtifactTag:
+		err := gh.DeleteRefOrg(a.org(), a.Repo, refPath(a.Kind, a.Name))
+		// Deleting the head branch of a PR may beat us to it
+		if gh.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return fmt.Errorf("unknown artifact kind %s", a.Kind)
+}
+
+// refPath returns the path of the branch or tag in the git refs api, e.g. heads/release/1.110.0
+func refPath(kind, name string) string {
+	if kind == ArtifactTag {
+		return "tags/" + name
+	}
+	return "heads/" + name
+}
+
+func refExists(rpo, ref string) (bool, error) {
+	return refExistsOrg(repo.GetOrg(rpo), rpo, ref)
+}
+
+func refExistsOrg(org, rpo, ref string) (bool, error) {
+	if _, err := gh.GetRefOrg(org, rpo, ref); err != nil {
+		if gh.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
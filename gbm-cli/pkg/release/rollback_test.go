@@ -0,0 +1,118 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/repo"
+)
+
+func TestRollback(t *testing.T) {
+
+	t.Run("It looks up branches and tags by their ref path", func(t *testing.T) {
+		assertEqual(t, refPath(ArtifactBranch, "rnmobile/release_1.110.0"), "heads/rnmobile/release_1.110.0")
+		assertEqual(t, refPath(ArtifactTag, "rnmobile/1.110.0"), "tags/rnmobile/1.110.0")
+	})
+
+	t.Run("It describes the artifact with its repo", func(t *testing.T) {
+		a := Artifact{Repo: "gutenberg-mobile", Kind: ArtifactBranch, Name: "release/1.110.0"}
+		assertEqual(t, a.String(), "branch release/1.110.0 on wordpress-mobile/gutenberg-mobile")
+	})
+
+	t.Run("It doesn't roll back blocked artifacts", func(t *testing.T) {
+		a := Artifact{Repo: "WordPress-iOS", Kind: ArtifactBranch, Name: "gutenberg/after_1.110.0", Blocked: "2 open PRs are based on it"}
+		if err := RollbackArtifact(a); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+
+	t.Run("It keeps the branches and tags of repos whose release PR was merged", func(t *testing.T) {
+		gb := repo.GetOrg("gutenberg") + "/gutenberg"
+		gbm := repo.GetOrg("gutenberg-mobile") + "/gutenberg-mobile"
+		api := fakeGitHub(t).
+			On("GET search/issues", 200, gh.SearchResult{}).
+			On("GET search/issues?q=*is:merged repo:"+gb, 200, gh.SearchResult{TotalCount: 1, Items: []gh.PullRequest{{Number: 7, State: "closed"}}}).
+			On("GET search/issues?q=*is:open repo:"+gbm, 200, gh.SearchResult{TotalCount: 1, Items: []gh.PullRequest{{Number: 8, State: "open"}}}).
+			On("GET repos/"+gb+"/git/ref/heads/rnmobile/release_1.110.0", 200, gh.Ref{}).
+			On("GET repos/"+gb+"/git/ref/tags/rnmobile/1.110.0", 200, gh.Ref{}).
+			On("GET repos/"+gbm+"/git/ref/heads/release/1.110.0", 200, gh.Ref{}).
+			On("PATCH repos/"+gbm+"/pulls/8", 200, gh.PullRequest{Number: 8, State: "closed"}).
+			On("DELETE repos/"+gbm+"/git/refs/heads/release/1.110.0", 204, "")
+
+		artifacts, err := FindArtifacts("1.110.0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		blocked := map[string]string{}
+		for _, a := range artifacts {
+			blocked[a.Kind+" "+a.Name] = a.Blocked
+		}
+		if _, ok := blocked["pr #7 "]; ok {
+			t.Fatal("Expected the merged PR not to be rolled back")
+		}
+		assertEqual(t, blocked["pr #8 "], "")
+		assertEqual(t, blocked["branch release/1.110.0"], "")
+		assertEqual(t, blocked["branch rnmobile/release_1.110.0"], "the release PR was merged")
+		assertEqual(t, blocked["tag rnmobile/1.110.0"], "the release PR was merged")
+		if len(artifacts) != 4 {
+			t.Fatalf("Expected 4 artifacts, got %v", artifacts)
+		}
+
+		for _, a := range artifacts {
+			if err := RollbackArtifact(a); err != nil && a.Blocked == "" {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if !api.Sent("DELETE repos/" + gbm + "/git/refs/heads/release/1.110.0") {
+			t.Fatalf("Expected the Gutenberg Mobile release branch to be deleted, got %v", api.Requests())
+		}
+		if api.Sent("DELETE repos/" + gb) {
+			t.Fatalf("Expected nothing to be deleted on %s, got %v", gb, api.Requests())
+		}
+	})
+
+	t.Run("It rolls back the open PR of a release that was aborted before", func(t *testing.T) {
+		gbm := repo.GetOrg("gutenberg-mobile") + "/gutenberg-mobile"
+		api := fakeGitHub(t).
+			On("GET search/issues", 200, gh.SearchResult{}).
+			On("GET search/issues?q=*is:open repo:"+gbm, 200, gh.SearchResult{TotalCount: 1, Items: []gh.PullRequest{{Number: 9, State: "open"}}})
+
+		artifacts, err := FindArtifacts("1.110.0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(artifacts) != 1 || artifacts[0].Pr.Number != 9 {
+			t.Fatalf("Expected the open PR #9, got %v", artifacts)
+		}
+		if !api.Sent("GET search/issues?q=is:pr label:release-process 1.110.0 in:title is:merged repo:" + gbm) {
+			t.Fatalf("Expected a search for the merged PRs, got %v", api.Requests())
+		}
+	})
+
+	t.Run("It finds and deletes the release branches on the fork", func(t *testing.T) {
+		t.Cleanup(func() { repo.ForkOwner = "" })
+		repo.ForkOwner = "my-fork"
+		api := fakeGitHub(t).
+			On("GET search/issues", 200, gh.SearchResult{}).
+			On("GET repos/"+repo.GetOrg("gutenberg-mobile")+"/gutenberg-mobile/git/ref/heads/release/1.110.0", 200, gh.Ref{}).
+			On("GET repos/my-fork/gutenberg-mobile/git/ref/heads/release/1.110.0", 200, gh.Ref{}).
+			On("DELETE repos/my-fork/gutenberg-mobile/git/refs/heads/release/1.110.0", 204, "")
+
+		artifacts, err := FindArtifacts("1.110.0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(artifacts) != 1 {
+			t.Fatalf("Expected the fork branch only, got %v", artifacts)
+		}
+		assertEqual(t, artifacts[0].String(), "branch release/1.110.0 on my-fork/gutenberg-mobile")
+
+		if err := RollbackArtifact(artifacts[0]); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !api.Sent("DELETE repos/my-fork/gutenberg-mobile/git/refs/heads/release/1.110.0") {
+			t.Fatalf("Expected the fork branch to be deleted, got %v", api.Requests())
+		}
+	})
+}
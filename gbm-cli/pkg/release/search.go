@@ -53,3 +53,17 @@ func FindIosReleasePr(version string) (gh.PullRequest, error) {
 func GetGbmRelease(version string) (gh.Release, error) {
 	return gh.GetReleaseByTag(repo.GutenbergMobileRepo, "v"+version)
 }
+
+// releasePrFilter returns the search for the release PRs of the version on rpo, narrowed by the qualifiers, e.g. is:open
+func releasePrFilter(rpo, version string, qualifiers ...string) gh.RepoFilter {
+	var queries []string
+	switch rpo {
+	case repo.GutenbergRepo:
+		queries = []string{"is:pr", fmt.Sprintf("label:\"%s\"", GbReleasePrLabel), fmt.Sprintf("v%s in:title", version)}
+	case repo.GutenbergMobileRepo:
+		queries = []string{"is:pr", "label:" + GbmReleasePrLabel, version + " in:title"}
+	default:
+		queries = []string{"is:pr", "label:" + IntegratePrLabel, fmt.Sprintf(IntegratePrTitle+" in:title", version)}
+	}
+	return gh.BuildRepoFilter(rpo, append(queries, qualifiers...)...)
+}
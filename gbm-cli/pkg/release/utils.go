@@ -12,6 +12,7 @@ import (
 
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/shell"
 )
 
 func CollectReleaseChanges(version string, changelog, relnotes []byte) ([]ReleaseChanges, error) {
@@ -193,18 +194,20 @@ func openInEditor(dir string, files []string) error {
 
 	fileArgs := strings.Join(files, " ")
 
-	if editor == "" {
-		editor = console.Ask("\nNo $EDITOR set. Enter the command to open your editor:")
+	// An answer overrides $EDITOR, e.g. `editor: skip` in the answers file
+	if editor == "" || console.HasAnswer("editor") {
+		// Nobody is there to edit the files with --yes, so skip rather than fail
+		editor = console.AskOr("editor", "\nNo $EDITOR set. Enter the command to open your editor (or skip):", "skip")
 	}
 
-	if editor == "" {
+	if strings.TrimSpace(editor) == "" || editor == "skip" {
 		console.Warn("No editor set. Manually edit or verify the following files before continuing:")
 		for _, f := range files {
 			console.Print(console.Row, f)
 		}
 		return nil
 	}
-	if open := console.Confirm(fmt.Sprintf("\nOpen '%s' with `%s`?", fileArgs, editor)); !open {
+	if open := console.Confirm("open_editor", fmt.Sprintf("\nOpen '%s' with `%s`?", fileArgs, editor)); !open {
 		console.Warn("Canceled opening the files in the editor. Manually edit the files before continuing")
 		return nil
 	}
@@ -212,7 +215,12 @@ func openInEditor(dir string, files []string) error {
 	for i, f := range files {
 		files[i] = filepath.Join(dir, f)
 	}
-	cmd := exec.Command(editor, files...)
+	// The editor can have arguments and quoted paths, e.g. `code --wait`
+	args, err := shell.SplitWords(editor)
+	if err != nil {
+		return fmt.Errorf("error parsing the editor command %q: %v", editor, err)
+	}
+	cmd := exec.Command(args[0], append(args[1:], files...)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
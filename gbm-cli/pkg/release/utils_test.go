@@ -0,0 +1,37 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+)
+
+func TestOpenInEditor(t *testing.T) {
+
+	t.Run("It runs an editor with quoted paths and arguments", func(t *testing.T) {
+		console.Yes = true
+		t.Cleanup(func() { console.Yes = false })
+
+		dir := filepath.Join(t.TempDir(), "my editor")
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		out := filepath.Join(dir, "args")
+		editor := filepath.Join(dir, "edit.sh")
+		if err := os.WriteFile(editor, []byte("#!/bin/sh\necho \"$@\" > \""+out+"\"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("EDITOR", "'"+editor+"' --wait")
+
+		if err := openInEditor("/repo", []string{"CHANGELOG.md"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, string(data), "--wait /repo/CHANGELOG.md\n")
+	})
+}
@@ -5,12 +5,14 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"text/template"
 )
 
-var TemplateFS embed.FS
+// TemplateFS holds the templates, the CLI sets it to the embedded templates
+var TemplateFS fs.FS = embed.FS{}
 
 type Template struct {
 	Path, Json string
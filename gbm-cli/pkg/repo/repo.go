@@ -3,8 +3,6 @@ package repo
 import (
 	"fmt"
 	"os"
-
-	"github.com/cli/go-gh/v2/pkg/auth"
 )
 
 const WordPressAndroidRepo = "WordPress-Android"
@@ -19,6 +17,21 @@ var (
 	WordPressOrg  string
 	AutomatticOrg string
 	ToolkitOrg    string
+
+	// ForkOwner is the user whose forks the release branches are pushed to.
+	// Unlike the orgs above, the upstream repos are still cloned and the PRs are opened against them.
+	ForkOwner string
+)
+
+// ForkRemote is the name of the git remote pointing to the fork
+const ForkRemote = "fork"
+
+// The orgs of the upstream repos, used unless the GBM_*_ORG variables are set
+const (
+	defaultWpMobileOrg   = "wordpress-mobile"
+	defaultWordPressOrg  = "WordPress"
+	defaultAutomatticOrg = "Automattic"
+	defaultToolkitOrg    = "wordpress-mobile"
 )
 
 func init() {
@@ -27,45 +40,56 @@ func init() {
 
 func InitOrgs() {
 	if gbmWpMobileOrg, ok := os.LookupEnv("GBM_WPMOBILE_ORG"); !ok {
-		WpMobileOrg = "wordpress-mobile"
+		WpMobileOrg = defaultWpMobileOrg
 	} else {
 		WpMobileOrg = gbmWpMobileOrg
 	}
 
 	if gbmWordPressOrg, ok := os.LookupEnv("GBM_WORDPRESS_ORG"); !ok {
-		WordPressOrg = "WordPress"
+		WordPressOrg = defaultWordPressOrg
 	} else {
 		WordPressOrg = gbmWordPressOrg
 	}
 
 	if gbmAutomatticOrg, ok := os.LookupEnv("GBM_AUTOMATTIC_ORG"); !ok {
-		AutomatticOrg = "Automattic"
+		AutomatticOrg = defaultAutomatticOrg
 	} else {
 		AutomatticOrg = gbmAutomatticOrg
 	}
 
 	if gbmToolkitOrg, ok := os.LookupEnv("GBM_TOOLKIT_ORG"); !ok {
-		ToolkitOrg = "wordpress-mobile"
+		ToolkitOrg = defaultToolkitOrg
 	} else {
 		ToolkitOrg = gbmToolkitOrg
 	}
 
+	ForkOwner = os.Getenv("GBM_FORK")
+
 }
 
 func GetOrg(repo string) string {
+	return org(repo, WpMobileOrg, WordPressOrg, AutomatticOrg, ToolkitOrg)
+}
+
+// UpstreamOrg returns the org of the upstream repo, ignoring the GBM_*_ORG variables
+func UpstreamOrg(repo string) string {
+	return org(repo, defaultWpMobileOrg, defaultWordPressOrg, defaultAutomatticOrg, defaultToolkitOrg)
+}
+
+func org(repo, wpMobile, wordPress, automattic, toolkit string) string {
 	switch repo {
 	case GutenbergRepo:
-		return WordPressOrg
+		return wordPress
 	case JetpackRepo:
-		return AutomatticOrg
+		return automattic
 	case GutenbergMobileRepo:
 		fallthrough
 	case WordPressAndroidRepo:
 		fallthrough
 	case WordPressIosRepo:
-		return WpMobileOrg
+		return wpMobile
 	case ReleaseToolkitGutenbergMobileRepo:
-		return ToolkitOrg
+		return toolkit
 	default:
 		return ""
 	}
@@ -77,10 +101,23 @@ func GetRepoPath(repo string) string {
 }
 
 func GetRepoHttpsPath(repo string) string {
-	org := GetOrg(repo)
-	token, _ := auth.TokenForHost("github.com")
-	if token != "" {
-		return fmt.Sprintf("https://%s@github.com/%s/%s", token, org, repo)
+	return httpsPath(GetOrg(repo), repo)
+}
+
+// GetForkRepoHttpsPath returns the path of the ForkOwner's fork of the repo
+func GetForkRepoHttpsPath(repo string) string {
+	return httpsPath(ForkOwner, repo)
+}
+
+// HeadRef returns the head of a PR for the branch, prefixed with the ForkOwner when using forks
+func HeadRef(branch string) string {
+	if ForkOwner == "" {
+		return branch
 	}
+	return ForkOwner + ":" + branch
+}
+
+// The token isn't part of the url, the git client passes it, see shell.NewGitCmd
+func httpsPath(org, repo string) string {
 	return fmt.Sprintf("https://github.com/%s/%s", org, repo)
 }
@@ -28,6 +28,25 @@ func TestInitOrgs(t *testing.T) {
 	})
 }
 
+func TestHeadRef(t *testing.T) {
+
+	t.Run("It returns the branch when not using a fork", func(t *testing.T) {
+		t.Cleanup(InitOrgs)
+		t.Setenv("GBM_FORK", "")
+		InitOrgs()
+		assertEqual(t, HeadRef("release/1.0.0"), "release/1.0.0")
+	})
+
+	t.Run("It prefixes the branch with the fork owner", func(t *testing.T) {
+		// cleanups run last in first, so the orgs are reset after the env is restored
+		t.Cleanup(InitOrgs)
+		t.Setenv("GBM_FORK", "octocat")
+		InitOrgs()
+
+		assertEqual(t, HeadRef("release/1.0.0"), "octocat:release/1.0.0")
+	})
+}
+
 func assertEqual(t testing.TB, got, want interface{}) {
 	t.Helper()
 	eq := reflect.DeepEqual(got, want)
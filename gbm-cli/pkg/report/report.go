@@ -0,0 +1,234 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+)
+
+// Set GBM_REPORT_DIR to archive the reports somewhere else than the user cache directory
+const DirEnv = "GBM_REPORT_DIR"
+
+// CliVersion is the version of the CLI written to the reports
+var CliVersion string
+
+// Repo is what a run did in a repo: where it started from and what it pushed
+type Repo struct {
+	Repo     string
+	Base     string `json:",omitempty"`
+	BaseSha  string `json:",omitempty"`
+	Branch   string `json:",omitempty"`
+	HeadSha  string `json:",omitempty"`
+	Pr       string `json:",omitempty"`
+	PrNumber int    `json:",omitempty"`
+}
+
+type CherryPick struct {
+	Repo string
+	Pr   int
+	Sha  string
+}
+
+type Skip struct {
+	Repo   string
+	Step   string
+	Reason string
+}
+
+// Report is the audit trail of a prepare or integrate run
+type Report struct {
+	Command     string
+	Version     string
+	CliVersion  string
+	User        string `json:",omitempty"`
+	Started     time.Time
+	Finished    *time.Time `json:",omitempty"`
+	Completed   bool
+	Inputs      map[string]string
+	Repos       []Repo
+	CherryPicks []CherryPick
+	Skipped     []Skip
+	Answers     []console.Answer
+	DebugLog    string `json:",omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	current *Report
+)
+
+// Start begins the report of the run. The Record functions are no-ops until a report is started.
+func Start(command, version string, inputs map[string]string) {
+	r := &Report{
+		Command:    command,
+		Version:    version,
+		CliVersion: CliVersion,
+		Started:    time.Now(),
+		Inputs:     inputs,
+	}
+	if user, err := gh.CurrentUser(); err != nil {
+		console.Debug("Unable to get the GitHub user for the run report: %v", err)
+	} else {
+		r.User = user.Login
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	current = r
+}
+
+// Current returns a copy of the report of the run, with the prompt answers so far
+func Current() (Report, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return Report{}, false
+	}
+	r := *current
+	r.Answers = console.Answered()
+	r.DebugLog = console.DebugLogPath()
+	return r, true
+}
+
+func update(f func(r *Report)) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		f(current)
+	}
+}
+
+func updateRepo(rpo string, f func(r *Repo)) {
+	update(func(r *Report) {
+		for i := range r.Repos {
+			if r.Repos[i].Repo == rpo {
+				f(&r.Repos[i])
+				return
+			}
+		}
+		r.Repos = append(r.Repos, Repo{Repo: rpo})
+		f(&r.Repos[len(r.Repos)-1])
+	})
+}
+
+// RecordBase records the ref and sha the release branch of the repo was created from
+func RecordBase(rpo, ref, sha string) {
+	updateRepo(rpo, func(r *Repo) {
+		r.Base = ref
+		r.BaseSha = sha
+	})
+}
+
+// RecordHead records the branch pushed to the repo and its sha
+func RecordHead(rpo, branch, sha string) {
+	updateRepo(rpo, func(r *Repo) {
+		r.Branch = branch
+		r.HeadSha = sha
+	})
+}
+
+// RecordPr records the PR created, or found, for the repo
+func RecordPr(rpo string, pr gh.PullRequest) {
+	updateRepo(rpo, func(r *Repo) {
+		r.Pr = pr.Url
+		r.PrNumber = pr.Number
+	})
+}
+
+func RecordCherryPick(rpo string, pr int, sha string) {
+	update(func(r *Report) {
+		r.CherryPicks = append(r.CherryPicks, CherryPick{Repo: rpo, Pr: pr, Sha: sha})
+	})
+}
+
+// RecordSkip records a step of the release that didn't run and why
+func RecordSkip(rpo, step, reason string) {
+	update(func(r *Report) {
+		r.Skipped = append(r.Skipped, Skip{Repo: rpo, Step: step, Reason: reason})
+	})
+}
+
+// Finish marks the run as completed. Reports saved without finishing are from failed or aborted runs.
+func Finish() {
+	update(func(r *Report) {
+		now := time.Now()
+		r.Completed = true
+		r.Finished = &now
+	})
+}
+
+// Dir returns the directory the reports are archived in, e.g. ~/.cache/gbm-cli/reports
+func Dir() (string, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "gbm-cli", "reports"), nil
+}
+
+// Save archives the report with the other reports of the release, e.g. reports/1.110.0/prepare-gb-20231020-101500.json
+func (r Report) Save() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, r.Version)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	if r.Finished == nil {
+		now := time.Now()
+		r.Finished = &now
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", r.Command, r.Started.Format("20060102-150405")))
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// Markdown renders the report as a collapsed section with the json report.
+// The debug log is left out, its path is local to the machine of the run.
+func (r Report) Markdown() (string, error) {
+	r.DebugLog = ""
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	summary := fmt.Sprintf("Release run report: `%s %s` with gbm-cli %s", r.Command, r.Version, r.CliVersion)
+	if r.User != "" {
+		summary += " by " + r.User
+	}
+	return fmt.Sprintf("<details>\n<summary>%s</summary>\n\n```json\n%s\n```\n</details>\n", summary, data), nil
+}
+
+// Comment posts the report as a comment on every PR of the run.
+// A failing PR doesn't stop the others, the errors are returned together.
+func (r Report) Comment() error {
+	body, err := r.Markdown()
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, rpo := range r.Repos {
+		if rpo.PrNumber == 0 {
+			continue
+		}
+		if err := gh.CreateComment(rpo.Repo, rpo.PrNumber, body); err != nil {
+			errs = append(errs, fmt.Errorf("error commenting on %s: %v", rpo.Pr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/gh"
+)
+
+func TestReport(t *testing.T) {
+	setup := func(t *testing.T) {
+		t.Helper()
+		t.Setenv(DirEnv, t.TempDir())
+		// Start looks up the GitHub user, the tests start the report without it
+		current = &Report{
+			Command:    "prepare-gb",
+			Version:    "1.110.0",
+			CliVersion: "v1.6.0",
+			Started:    time.Date(2023, 10, 20, 10, 15, 0, 0, time.UTC),
+			Inputs:     map[string]string{"no-tag": "true"},
+		}
+		t.Cleanup(func() { current = nil })
+	}
+
+	t.Run("It records what the run did per repo", func(t *testing.T) {
+		setup(t)
+
+		RecordBase("gutenberg", "trunk", "abc123")
+		RecordCherryPick("gutenberg", 123, "def456")
+		RecordHead("gutenberg", "rnmobile/release_1.110.0", "fed654")
+		RecordPr("gutenberg", gh.PullRequest{Number: 42, Url: "https://github.com/WordPress/gutenberg/pull/42"})
+		RecordSkip("gutenberg", "tag", "--no-tag")
+
+		r, ok := Current()
+		if !ok {
+			t.Fatal("Expected a report")
+		}
+		want := Repo{
+			Repo:     "gutenberg",
+			Base:     "trunk",
+			BaseSha:  "abc123",
+			Branch:   "rnmobile/release_1.110.0",
+			HeadSha:  "fed654",
+			Pr:       "https://github.com/WordPress/gutenberg/pull/42",
+			PrNumber: 42,
+		}
+		if len(r.Repos) != 1 || r.Repos[0] != want {
+			t.Fatalf("got %+v", r.Repos)
+		}
+		if len(r.CherryPicks) != 1 || r.CherryPicks[0].Sha != "def456" {
+			t.Fatalf("got %+v", r.CherryPicks)
+		}
+		if len(r.Skipped) != 1 || r.Skipped[0].Reason != "--no-tag" {
+			t.Fatalf("got %+v", r.Skipped)
+		}
+	})
+
+	t.Run("It ignores records without a started report", func(t *testing.T) {
+		current = nil
+
+		RecordBase("gutenberg", "trunk", "abc123")
+
+		if _, ok := Current(); ok {
+			t.Fatal("Expected no report")
+		}
+	})
+
+	t.Run("It archives the report with the release", func(t *testing.T) {
+		setup(t)
+		RecordBase("gutenberg-mobile", "trunk", "abc123")
+		Finish()
+
+		r, _ := Current()
+		path, err := r.Save()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := filepath.Join(os.Getenv(DirEnv), "1.110.0", "prepare-gb-20231020-101500.json"); path != want {
+			t.Fatalf("got %s, want %s", path, want)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved := Report{}
+		if err := json.Unmarshal(data, &saved); err != nil {
+			t.Fatal(err)
+		}
+		if !saved.Completed || saved.Repos[0].BaseSha != "abc123" || saved.Inputs["no-tag"] != "true" {
+			t.Fatalf("got %+v", saved)
+		}
+	})
+
+	t.Run("It renders the report as a collapsed comment", func(t *testing.T) {
+		setup(t)
+		r, _ := Current()
+		r.User = "wrangler"
+
+		body, err := r.Markdown()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(body, "<details>\n<summary>Release run report: `prepare-gb 1.110.0` with gbm-cli v1.6.0 by wrangler</summary>") {
+			t.Fatalf("got %s", body)
+		}
+		if !strings.Contains(body, "```json\n{\n  \"Command\": \"prepare-gb\"") {
+			t.Fatalf("Expected the json report, got %s", body)
+		}
+	})
+
+	t.Run("It leaves the debug log and an unfinished time out of the comment", func(t *testing.T) {
+		setup(t)
+		r, _ := Current()
+		r.DebugLog = "/home/wrangler/.cache/gbm-cli/logs/run.debug.log"
+
+		body, err := r.Markdown()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Contains(body, "DebugLog") || strings.Contains(body, "Finished") {
+			t.Fatalf("got %s", body)
+		}
+	})
+
+	t.Run("It comments on the other PRs when one fails", func(t *testing.T) {
+		setup(t)
+		t.Setenv("GH_TOKEN", "test")
+		fake := gh.NewFake().
+			On("POST repos/wordpress-mobile/gutenberg-mobile/issues/7/comments", 201, map[string]int{"id": 1})
+		prev := gh.Transport
+		gh.Transport = fake
+		t.Cleanup(func() { gh.Transport = prev })
+
+		RecordPr("gutenberg", gh.PullRequest{Number: 42, Url: "https://github.com/WordPress/gutenberg/pull/42"})
+		RecordPr("gutenberg-mobile", gh.PullRequest{Number: 7, Url: "https://github.com/wordpress-mobile/gutenberg-mobile/pull/7"})
+		r, _ := Current()
+
+		err := r.Comment()
+		if err == nil || !strings.Contains(err.Error(), "gutenberg/pull/42") {
+			t.Fatalf("Expected the failing PR in the error, got %v", err)
+		}
+		if !fake.Sent("POST repos/wordpress-mobile/gutenberg-mobile/issues/7/comments") {
+			t.Fatalf("Expected a comment on the gutenberg-mobile PR, got %v", fake.Requests())
+		}
+	})
+}
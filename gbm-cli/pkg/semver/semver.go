@@ -15,6 +15,7 @@ type SemVer interface {
 	IsScheduledRelease() bool
 	IsPatchRelease() bool
 	Parse(version string) error
+	Compare(other SemVer) int
 }
 
 func NewSemVer(version string) (SemVer, error) {
@@ -58,3 +59,20 @@ func (s *semver) Parse(version string) error {
 	_, err := fmt.Sscanf(version, "%d.%d.%d", &s.Major, &s.Minor, &s.Patch)
 	return err
 }
+
+// Compare returns -1, 0 or 1 if the version is lower, equal or higher than other
+func (s *semver) Compare(other SemVer) int {
+	o := &semver{}
+	// ignore errors here because other is already a valid version
+	_ = o.Parse(other.String())
+
+	for _, d := range []int{s.Major - o.Major, s.Minor - o.Minor, s.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return 0
+}
@@ -55,6 +55,19 @@ func TestSemVer(t *testing.T) {
 		}
 	})
 
+	t.Run("It compares versions", func(t *testing.T) {
+		v, err := NewSemVer("1.109.1")
+		assertNotError(t, err)
+
+		for other, want := range map[string]int{"1.109.1": 0, "1.110.0": -1, "1.9.0": 1, "v1.109.0": 1, "2.0.0": -1} {
+			o, err := NewSemVer(other)
+			assertNotError(t, err)
+			if got := v.Compare(o); got != want {
+				t.Fatalf("Expected comparing to %s to be %d, got %d", other, want, got)
+			}
+		}
+	})
+
 }
 
 func assertError(t *testing.T, err error) {
@@ -0,0 +1,44 @@
+package shell
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// gitAuthEnv passes the GitHub token to git as an auth header through the environment.
+// Unlike a token in the remote url, it never shows up in command lines, logs or the git config.
+// Config from the environment needs git 2.31 or later.
+func gitAuthEnv() []string {
+	token, _ := auth.TokenForHost("github.com")
+	if token == "" {
+		return nil
+	}
+	// Keep the config the user may already pass the same way
+	n, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	basic := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.https://github.com/.extraheader", n),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=AUTHORIZATION: basic %s", n, basic),
+	}
+}
+
+var userInfoRe = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s]+@`)
+
+// Redact removes the credentials of the urls in s, e.g. https://token@github.com becomes https://github.com
+func Redact(s string) string {
+	return userInfoRe.ReplaceAllString(s, "$1")
+}
+
+func redactAll(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, a := range args {
+		redacted[i] = Redact(a)
+	}
+	return redacted
+}
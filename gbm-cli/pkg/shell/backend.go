@@ -0,0 +1,121 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Invocation is a command a client is about to run
+type Invocation struct {
+	Bin     string
+	Args    []string
+	Dir     string
+	Env     []string
+	Verbose bool
+
+	// builds the actual command, which may differ from Bin and Args (e.g. npm via nvm)
+	build func() *exec.Cmd
+}
+
+// String returns the command line without the credentials of urls, e.g. `git push origin HEAD`
+func (i Invocation) String() string {
+	return strings.Join(append([]string{i.Bin}, redactAll(i.Args)...), " ")
+}
+
+// Backend runs the invocations of the shell clients
+type Backend interface {
+	Run(ctx context.Context, inv Invocation) (Result, error)
+}
+
+type execBackend struct{}
+
+func (execBackend) Run(ctx context.Context, inv Invocation) (Result, error) {
+	return execute(ctx, inv.build(), inv.Dir, inv.Verbose)
+}
+
+// Fake is a Backend that records invocations instead of running them.
+// Results are replayed from the scripts set with On, otherwise commands succeed with no output.
+type Fake struct {
+	mu      sync.Mutex
+	calls   []Invocation
+	scripts []script
+}
+
+type script struct {
+	prefix   string
+	stdout   string
+	exitCode int
+
+	// limited scripts only apply the remaining number of times
+	limited   bool
+	remaining int
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// On scripts the result for commands starting with prefix, e.g. "git diff --exit-code".
+// The latest matching script wins, so a default can be overridden later in a test.
+func (f *Fake) On(prefix, stdout string, exitCode int) *Fake {
+	return f.OnTimes(prefix, stdout, exitCode, 0)
+}
+
+// OnTimes is like On but the script only applies n times. n of 0 applies it forever.
+func (f *Fake) OnTimes(prefix, stdout string, exitCode int, n int) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts = append(f.scripts, script{prefix: prefix, stdout: stdout, exitCode: exitCode, limited: n > 0, remaining: n})
+	return f
+}
+
+func (f *Fake) Run(ctx context.Context, inv Invocation) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, inv)
+	res := Result{Bin: inv.Bin, Args: inv.Args, Dir: inv.Dir}
+
+	if err := ctx.Err(); err != nil {
+		res.ExitCode = -1
+		return res, &CmdError{Result: res, Err: err}
+	}
+
+	cmdline := inv.String()
+	for i := len(f.scripts) - 1; i >= 0; i-- {
+		s := &f.scripts[i]
+		if !strings.HasPrefix(cmdline, s.prefix) || (s.limited && s.remaining == 0) {
+			continue
+		}
+		if s.limited {
+			s.remaining--
+		}
+		res.Stdout = s.stdout
+		res.ExitCode = s.exitCode
+		break
+	}
+
+	if res.ExitCode != 0 {
+		return res, &CmdError{Result: res, Err: fmt.Errorf("exit status %d", res.ExitCode)}
+	}
+	return res, nil
+}
+
+// Calls returns the recorded invocations
+func (f *Fake) Calls() []Invocation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Invocation{}, f.calls...)
+}
+
+// Commands returns the recorded invocations as command lines
+func (f *Fake) Commands() []string {
+	cmds := []string{}
+	for _, c := range f.Calls() {
+		cmds = append(cmds, c.String())
+	}
+	return cmds
+}
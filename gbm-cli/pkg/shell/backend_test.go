@@ -0,0 +1,83 @@
+package shell
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFake(t *testing.T) {
+
+	t.Run("It records the invocations", func(t *testing.T) {
+		fake := NewFake().On("git status", "# branch.head release/1.0.0\x00", 0)
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake, Env: []string{"GIT_TERMINAL_PROMPT=0"}})
+
+		assertNoError(t, git.Switch("-c", "release/1.0.0"))
+		assertNoError(t, git.Push())
+
+		want := []string{"git switch -c release/1.0.0", "git status --porcelain=v2 --branch -z", "git push origin HEAD:refs/heads/release/1.0.0"}
+		if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", got, want)
+		}
+
+		call := fake.Calls()[0]
+		assertEqual(t, call.Dir, "/repo")
+		assertEqual(t, call.Env[0], "GIT_TERMINAL_PROMPT=0")
+	})
+
+	t.Run("It passes the token to git in the environment", func(t *testing.T) {
+		t.Setenv("GH_TOKEN", "s3cr3t")
+		t.Setenv("GIT_CONFIG_COUNT", "")
+		fake := NewFake()
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		assertNoError(t, git.Clone("https://github.com/WordPress/gutenberg", "."))
+
+		call := fake.Calls()[0]
+		if strings.Contains(call.String(), "s3cr3t") {
+			t.Fatalf("Expected no token in the command line, got %s", call)
+		}
+		env := strings.Join(call.Env, "\n")
+		if !strings.Contains(env, "GIT_CONFIG_KEY_0=http.https://github.com/.extraheader") ||
+			!strings.Contains(env, "GIT_CONFIG_VALUE_0=AUTHORIZATION: basic "+base64.StdEncoding.EncodeToString([]byte("x-access-token:s3cr3t"))) {
+			t.Fatalf("Expected the auth header in the environment, got %v", call.Env)
+		}
+	})
+
+	t.Run("It replays the scripted results", func(t *testing.T) {
+		fake := NewFake().On("git status", "u UU N... 100644 100644 100644 100644 a1 b1 c1 a.txt\x00u AA N... 000000 100644 100644 100644 a2 b2 c2 b.txt\x00", 0)
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		conflicts, err := git.StatConflicts()
+		assertNoError(t, err)
+		if !reflect.DeepEqual(conflicts, []string{"a.txt", "b.txt"}) {
+			t.Fatalf("got %v", conflicts)
+		}
+	})
+
+	t.Run("It fails the command with the scripted exit code", func(t *testing.T) {
+		fake := NewFake().OnTimes("npm ci", "", 1, 1)
+		npm := NewNpmCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		if err := npm.Ci(); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		assertEqual(t, npm.LastResult().ExitCode, 1)
+
+		// the script only applied once
+		assertNoError(t, npm.Ci())
+	})
+
+	t.Run("It fails when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		fake := NewFake()
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake, Ctx: ctx})
+
+		if err := git.Fetch("trunk"); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package shell
 
 type BundlerCmds interface {
+	Results
 	Install(...string) error
 	PodInstall(...string) error
 }
@@ -1,85 +1,238 @@
 package shell
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
 )
 
 type CmdProps struct {
 	Dir     string
 	Verbose bool
+
+	// Wrapper is prepended to the command, e.g. `docker run --rm -v {dir}:/src -w /src image`.
+	// Any `{dir}` in the wrapper is replaced with the directory the command runs in.
+	Wrapper []string
+
+	// Env is added to the environment of the commands, e.g. "CI=true"
+	Env []string
+
+	// Ctx cancels running commands. Defaults to the root context, see Cancel.
+	Ctx context.Context
+
+	// Timeout limits how long each command of the client can run. No limit when zero.
+	Timeout time.Duration
+
+	// Backend runs the commands. Defaults to executing them, see Fake for tests.
+	Backend Backend
+}
+
+func (cp CmdProps) backend() Backend {
+	if cp.Backend == nil {
+		return execBackend{}
+	}
+	return cp.Backend
+}
+
+func (cp CmdProps) context() (context.Context, context.CancelFunc) {
+	ctx := cp.Ctx
+	if ctx == nil {
+		ctx = Context()
+	}
+	if cp.Timeout > 0 {
+		return context.WithTimeout(ctx, cp.Timeout)
+	}
+	return context.WithCancel(ctx)
 }
 
 type client struct {
 	cmd       func(...string) error
 	cmdInPath func(string, ...string) error
+	output    func(...string) (string, error)
 	dir       string
+	last      Result
 }
 
-func execute(cmd *exec.Cmd, dir string, verbose bool) error {
+// Results can be inspected after running a command
+type Results interface {
+	LastResult() Result
+}
+
+// Runs the command, capturing its output and tee'ing it to the log file.
+// When verbose the output is also streamed to stdout and stderr.
+// The command and its children are killed when ctx is done.
+func execute(ctx context.Context, cmd *exec.Cmd, dir string, verbose bool) (Result, error) {
 	cmd.Dir = dir
+	res := Result{Bin: cmd.Path, Args: cmd.Args[1:], Dir: dir}
+
+	var stdout, stderr bytes.Buffer
+	outs := []io.Writer{&stdout, logWriter{}}
+	errs := []io.Writer{&stderr, logWriter{}}
 	if verbose {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		outs = append(outs, os.Stdout)
+		errs = append(errs, os.Stderr)
+	}
+	cmd.Stdout = io.MultiWriter(outs...)
+	cmd.Stderr = io.MultiWriter(errs...)
+	setProcessGroup(cmd)
+
+	// Don't hang on output pipes held open by orphaned grandchildren
+	cmd.WaitDelay = 5 * time.Second
+
+	logf("=== %s [%s] %s\n", time.Now().Format(time.RFC3339), dir, res)
+	start := time.Now()
+
+	err := ctx.Err()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err == nil {
+		running.Add(1)
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				killProcessGroup(cmd)
+			case <-done:
+			}
+		}()
+		err = cmd.Wait()
+		close(done)
+		running.Done()
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+	}
+
+	res.Duration = time.Since(start)
+	res.Stdout = stdout.String()
+	res.Stderr = stderr.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	default:
+		res.ExitCode = -1
+	}
+	record(res)
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out: %w", err)
+	}
+	if err != nil {
+		return res, &CmdError{Result: res, Err: err}
 	}
-	return cmd.Run()
+	return res, nil
+}
+
+// Builds the command for name, running it through the wrapper if one is set
+func command(cp CmdProps, dir, name string, args ...string) *exec.Cmd {
+	cmd := wrap(cp, dir, name, args...)
+	if len(cp.Env) != 0 {
+		cmd.Env = append(os.Environ(), cp.Env...)
+	}
+	return cmd
+}
+
+func wrap(cp CmdProps, dir, name string, args ...string) *exec.Cmd {
+	if len(cp.Wrapper) == 0 {
+		return exec.Command(name, args...)
+	}
+	wrapped := []string{}
+	for _, w := range cp.Wrapper {
+		wrapped = append(wrapped, strings.ReplaceAll(w, "{dir}", dir))
+	}
+	wrapped = append(wrapped, name)
+	wrapped = append(wrapped, args...)
+	return exec.Command(wrapped[0], wrapped[1:]...)
+}
+
+// newClient sets up a client for bin, running the commands returned by build
+func newClient(cp CmdProps, bin string, build func(dir string, cmds ...string) *exec.Cmd) *client {
+	c := &client{dir: cp.Dir}
+	backend := cp.backend()
+	run := func(dir string, verbose bool, cmds ...string) (Result, error) {
+		ctx, cancel := cp.context()
+		defer cancel()
+
+		inv := Invocation{
+			Bin:     bin,
+			Args:    cmds,
+			Dir:     dir,
+			Env:     cp.Env,
+			Verbose: verbose,
+			build:   func() *exec.Cmd { return build(dir, cmds...) },
+		}
+		res, err := backend.Run(ctx, inv)
+		c.last = res
+		return res, err
+	}
+
+	c.cmd = func(cmds ...string) error {
+		_, err := run(cp.Dir, cp.Verbose, cmds...)
+		return err
+	}
+	c.cmdInPath = func(path string, cmds ...string) error {
+		_, err := run(path, cp.Verbose, cmds...)
+		return err
+	}
+	// output is used for queries so it never streams to the console
+	c.output = func(cmds ...string) (string, error) {
+		res, err := run(cp.Dir, false, cmds...)
+		return res.Stdout, err
+	}
+	return c
 }
 
 func NewNpmCmd(cp CmdProps) NpmCmds {
-	return &client{
-		cmd: func(cmds ...string) error {
-			cmd := switchNodeCmd(cmds...)
+	return newClient(cp, "npm", func(dir string, cmds ...string) *exec.Cmd {
+		cmd := nodeCommand(DetectNodeManager(), FindNodeRequirement(dir), cmds...)
+		if cmd.Env == nil {
 			cmd.Env = os.Environ()
-			return execute(cmd, cp.Dir, cp.Verbose)
-		},
-		cmdInPath: func(path string, cmds ...string) error {
-			cmd := switchNodeCmd(cmds...)
-			return execute(cmd, path, cp.Verbose)
-		},
-		dir: cp.Dir,
-	}
+		}
+		cmd.Env = append(cmd.Env, cp.Env...)
+		return cmd
+	})
 }
 
 func NewGitCmd(cp CmdProps) GitCmds {
-	return &client{
-		cmd: func(cmds ...string) error {
-			cmd := exec.Command("git", cmds...)
-			return execute(cmd, cp.Dir, cp.Verbose)
-		},
-		cmdInPath: func(path string, cmds ...string) error {
-			cmd := exec.Command("git", cmds...)
-			return execute(cmd, path, cp.Verbose)
-		},
-		dir: cp.Dir,
-	}
+	cp.Env = append(append([]string{}, cp.Env...), gitAuthEnv()...)
+	return newClient(cp, "git", func(dir string, cmds ...string) *exec.Cmd {
+		return command(cp, dir, "git", cmds...)
+	})
 }
 
 func NewBundlerCmd(cp CmdProps) BundlerCmds {
-	return &client{
-		cmd: func(cmds ...string) error {
-			cmd := exec.Command("bundle", cmds...)
-			return execute(cmd, cp.Dir, cp.Verbose)
-		},
-		cmdInPath: func(path string, cmds ...string) error {
-			cmd := exec.Command("bundle", cmds...)
-			return execute(cmd, path, cp.Verbose)
-		},
-		dir: cp.Dir,
-	}
+	return newClient(cp, "bundle", func(dir string, cmds ...string) *exec.Cmd {
+		return command(cp, dir, "bundle", cmds...)
+	})
 }
 
 func NewRakeCmd(cp CmdProps) RakeCmds {
-	return &client{
-		cmd: func(cmds ...string) error {
-			cmd := exec.Command("rake", cmds...)
-			return execute(cmd, cp.Dir, cp.Verbose)
-		},
-		cmdInPath: func(path string, cmds ...string) error {
-			cmd := exec.Command("rake", cmds...)
-			return execute(cmd, path, cp.Verbose)
-		},
-		dir: cp.Dir,
-	}
+	return newClient(cp, "rake", func(dir string, cmds ...string) *exec.Cmd {
+		return command(cp, dir, "rake", cmds...)
+	})
+}
+
+// NewExecCmd returns a client for an arbitrary executable
+func NewExecCmd(bin string, cp CmdProps) ExecCmds {
+	return newClient(cp, bin, func(dir string, cmds ...string) *exec.Cmd {
+		return command(cp, dir, bin, cmds...)
+	})
+}
+
+// LastResult returns the result of the last command the client ran
+func (c *client) LastResult() Result {
+	return c.last
 }
 
 // common commands
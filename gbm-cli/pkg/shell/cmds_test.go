@@ -0,0 +1,192 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+)
+
+func TestExecute(t *testing.T) {
+
+	t.Run("It captures the output and exit code", func(t *testing.T) {
+		c := NewExecCmd("sh", CmdProps{Dir: t.TempDir()})
+		err := c.Exec("-c", "echo out; echo err >&2")
+		assertNoError(t, err)
+
+		res := c.LastResult()
+		assertEqual(t, res.Stdout, "out\n")
+		assertEqual(t, res.Stderr, "err\n")
+		assertEqual(t, res.ExitCode, 0)
+	})
+
+	t.Run("It returns the tail of stderr when a command fails", func(t *testing.T) {
+		c := NewExecCmd("sh", CmdProps{Dir: t.TempDir()})
+		err := c.Exec("-c", "for i in $(seq 1 30); do echo line$i >&2; done; exit 3")
+
+		var cmdErr *CmdError
+		if !errors.As(err, &cmdErr) {
+			t.Fatalf("Expected a CmdError, got %v", err)
+		}
+		assertEqual(t, cmdErr.Result.ExitCode, 3)
+
+		msg := cmdErr.Error()
+		if !strings.Contains(msg, "line30") || strings.Contains(msg, "line10\n") {
+			t.Fatalf("Expected only the last %d lines of stderr, got %s", StderrTailLines, msg)
+		}
+	})
+
+	t.Run("It kills the command and its children when timing out", func(t *testing.T) {
+		c := NewExecCmd("sh", CmdProps{Dir: t.TempDir(), Timeout: 100 * time.Millisecond})
+		start := time.Now()
+		err := c.Exec("-c", "sleep 10 & sleep 10")
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected a timeout, got %v", err)
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Expected the command to be killed")
+		}
+	})
+
+	t.Run("It does not run commands once the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c := NewExecCmd("true", CmdProps{Dir: t.TempDir(), Ctx: ctx})
+		if err := c.Exec(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected a canceled error, got %v", err)
+		}
+	})
+
+	t.Run("It writes the output to the log file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "run.log")
+		assertNoError(t, OpenLog(path))
+		defer func() {
+			logFile.Close()
+			logFile = nil
+		}()
+
+		c := NewExecCmd("echo", CmdProps{Dir: t.TempDir()})
+		assertNoError(t, c.Exec("logged"))
+
+		data, err := os.ReadFile(path)
+		assertNoError(t, err)
+		if !strings.Contains(string(data), "echo logged\nlogged\n") {
+			t.Fatalf("Expected the command and output in the log, got %s", data)
+		}
+	})
+
+	t.Run("It keeps the credentials of urls out of the log, the history and the errors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "run.log")
+		assertNoError(t, OpenLog(path))
+		defer func() {
+			logFile.Close()
+			logFile = nil
+			history = nil
+		}()
+
+		c := NewExecCmd("sh", CmdProps{Dir: t.TempDir()})
+		err := c.Exec("-c", "exit 3", "https://s3cr3t@github.com/WordPress/gutenberg")
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+
+		data, rerr := os.ReadFile(path)
+		assertNoError(t, rerr)
+		h := History()
+		for where, text := range map[string]string{
+			"log":     string(data),
+			"history": strings.Join(h[len(h)-1].Args, " ") + h[len(h)-1].String(),
+			"error":   err.Error(),
+		} {
+			if strings.Contains(text, "s3cr3t") {
+				t.Fatalf("Expected no token in the %s, got %s", where, text)
+			}
+			if !strings.Contains(text, "https://github.com/WordPress/gutenberg") {
+				t.Fatalf("Expected the redacted url in the %s, got %s", where, text)
+			}
+		}
+
+		info, serr := os.Stat(path)
+		assertNoError(t, serr)
+		assertEqual(t, info.Mode().Perm(), os.FileMode(0600))
+	})
+
+	t.Run("It records the command without credentials in the debug log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "run.debug.log")
+		assertNoError(t, console.OpenDebugLog(path))
+		defer console.CloseDebugLog()
+
+		c := NewExecCmd("true", CmdProps{Dir: t.TempDir()})
+		assertNoError(t, c.Exec("https://s3cr3t@github.com/WordPress/gutenberg"))
+
+		data, err := os.ReadFile(path)
+		assertNoError(t, err)
+		if strings.Contains(string(data), "s3cr3t") || !strings.Contains(string(data), "true https://github.com/WordPress/gutenberg") {
+			t.Fatalf("Expected the redacted command in the debug log, got %s", data)
+		}
+		info, err := os.Stat(path)
+		assertNoError(t, err)
+		assertEqual(t, info.Mode().Perm(), os.FileMode(0600))
+	})
+
+	t.Run("It only keeps the tail of the output of the last commands", func(t *testing.T) {
+		prevLimit, prevLines := HistoryLimit, StderrTailLines
+		HistoryLimit, StderrTailLines = 2, 1
+		defer func() {
+			HistoryLimit, StderrTailLines = prevLimit, prevLines
+			history = nil
+		}()
+
+		for _, arg := range []string{"a", "b", "c"} {
+			assertNoError(t, NewExecCmd("printf", CmdProps{Dir: t.TempDir()}).Exec(arg+"\\nlast"))
+		}
+
+		h := History()
+		assertEqual(t, len(h), 2)
+		assertEqual(t, h[0].Args[0], "b\\nlast")
+		assertEqual(t, h[1].Stdout, "last")
+	})
+
+	t.Run("It waits for the running commands to exit when stopping", func(t *testing.T) {
+		t.Cleanup(func() { rootCtx, cancelRoot = context.WithCancel(context.Background()) })
+
+		c := NewExecCmd("sleep", CmdProps{Dir: t.TempDir()})
+		exited := make(chan struct{})
+		go func() {
+			c.Exec("10")
+			close(exited)
+		}()
+		// Give the command time to start
+		time.Sleep(100 * time.Millisecond)
+
+		if !Stop(5 * time.Second) {
+			t.Fatal("Expected the command to exit before the timeout")
+		}
+		select {
+		case <-exited:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the command to have exited when Stop returned")
+		}
+	})
+}
+
+func assertEqual(t testing.TB, got, want interface{}) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
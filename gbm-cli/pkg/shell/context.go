@@ -0,0 +1,50 @@
+package shell
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StopTimeout is how long Stop waits for the killed commands to exit
+const StopTimeout = 10 * time.Second
+
+var (
+	rootCtx    context.Context
+	cancelRoot context.CancelFunc
+
+	// running tracks the started commands until they exit
+	running sync.WaitGroup
+)
+
+func init() {
+	rootCtx, cancelRoot = context.WithCancel(context.Background())
+}
+
+// Context returns the root context commands run with when CmdProps.Ctx is not set
+func Context() context.Context {
+	return rootCtx
+}
+
+// Cancel cancels the root context, killing every running command and its children
+func Cancel() {
+	cancelRoot()
+}
+
+// Stop cancels the root context and waits up to timeout for the running commands to exit,
+// e.g. before removing the directories they write to. It returns false if some are still running.
+func Stop(timeout time.Duration) bool {
+	Cancel()
+
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,10 @@
+package shell
+
+type ExecCmds interface {
+	Results
+	Exec(...string) error
+}
+
+func (c *client) Exec(args ...string) error {
+	return c.cmd(args...)
+}
@@ -1,21 +1,26 @@
 package shell
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
 )
 
 type GitCmds interface {
+	Results
+	GitQueries
 	Clone(...string) error
 	Switch(...string) error
 	CommitAll(string, ...interface{}) error
 	Push() error
-	RemoteExists(string, string) bool
+	PushWith(PushOptions) error
+	RemoteExists(string, string) (bool, error)
+	RemoteSha(string, string) (string, error)
 	Submodule(...string) error
 	Fetch(...string) error
+	FetchRemote(string, ...string) error
 	SetRemoteBranches(...string) error
 	AddRemote(...string) error
 	SetUpstreamTo(...string) error
@@ -24,6 +29,10 @@ type GitCmds interface {
 	Log(...string) error
 	CherryPick(string) error
 	StatConflicts() ([]string, error)
+	AbortCherryPick() error
+	ResetHard(string) error
+	Stash(string) (bool, error)
+	StashPop() error
 }
 
 func (c *client) Clone(args ...string) error {
@@ -38,7 +47,12 @@ func (c *client) Switch(args ...string) error {
 
 func (c *client) CommitAll(format string, args ...interface{}) error {
 
-	if c.IsPorcelain() {
+	// commit -a only picks up tracked files
+	status, err := c.Status()
+	if err != nil {
+		return err
+	}
+	if !status.HasTrackedChanges() {
 		console.Warn("No changes to commit")
 		return nil
 	}
@@ -46,13 +60,85 @@ func (c *client) CommitAll(format string, args ...interface{}) error {
 	return c.cmd("commit", "-am", message)
 }
 
+// ProtectedBranches are never pushed to by the release scripts
+var ProtectedBranches = []string{"trunk", "main", "master", "develop"}
+
+// PushOptions configures PushWith
+type PushOptions struct {
+	// Remote to push to, defaults to origin. Set it to the fork remote when pushing to a fork.
+	Remote string
+
+	// Branch is the remote branch to push HEAD to, required
+	Branch string
+
+	// SetUpstream makes the remote branch the upstream of the current branch
+	SetUpstream bool
+
+	// ForceWithLease overwrites the remote branch as long as it still points to ExpectedSha.
+	// Without an ExpectedSha git compares against the remote tracking branch.
+	ForceWithLease bool
+	ExpectedSha    string
+}
+
+// Push pushes HEAD to the same branch on origin
 func (c *client) Push() error {
-	return c.cmd("push", "origin", "HEAD")
+	branch, err := c.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("unable to determine the branch to push: %v", err)
+	}
+	if branch == "" {
+		return fmt.Errorf("unable to push, HEAD is not on a branch")
+	}
+	return c.PushWith(PushOptions{Branch: branch})
+}
+
+// PushWith pushes HEAD with the options, refusing to push to a protected branch
+func (c *client) PushWith(opts PushOptions) error {
+	if opts.Remote == "" {
+		opts.Remote = "origin"
+	}
+	if opts.Branch == "" {
+		return fmt.Errorf("the branch to push to is required")
+	}
+	for _, p := range ProtectedBranches {
+		if opts.Branch == p {
+			return fmt.Errorf("refusing to push to the protected branch %s", p)
+		}
+	}
+
+	args := []string{"push"}
+	if opts.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	if opts.ForceWithLease {
+		lease := "--force-with-lease=" + opts.Branch
+		if opts.ExpectedSha != "" {
+			lease += ":" + opts.ExpectedSha
+		}
+		args = append(args, lease)
+	}
+	return c.cmd(append(args, opts.Remote, "HEAD:refs/heads/"+opts.Branch)...)
+}
+
+// RemoteSha returns the sha of the branch on the remote, or "" if the branch does not exist
+func (c *client) RemoteSha(remote, branch string) (string, error) {
+	out, err := c.output("ls-remote", "--exit-code", "--heads", remote, "refs/heads/"+branch)
+	if err != nil {
+		// --exit-code exits with 2 when no matching refs are found
+		var cmdErr *CmdError
+		if errors.As(err, &cmdErr) && cmdErr.Result.ExitCode == 2 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(strings.SplitN(out, "\t", 2)[0]), nil
 }
 
-func (c *client) RemoteExists(remote, branch string) bool {
-	err := c.cmd("ls-remote", "--exit-code", "--heads", remote, branch)
-	return err == nil
+// RemoteExists checks if the branch exists on the remote.
+// Errors reaching the remote are returned rather than treated as a missing branch.
+func (c *client) RemoteExists(remote, branch string) (bool, error) {
+	sha, err := c.RemoteSha(remote, branch)
+	return sha != "", err
 }
 
 func (c *client) Submodule(args ...string) error {
@@ -67,6 +153,12 @@ func (c *client) Fetch(args ...string) error {
 	return c.cmd(fetch...)
 }
 
+// FetchRemote fetches from a remote other than origin, e.g. the fork
+func (c *client) FetchRemote(remote string, args ...string) error {
+	fetch := append([]string{"fetch", remote}, args...)
+	return c.cmd(fetch...)
+}
+
 func (c *client) SetRemoteBranches(args ...string) error {
 	checkout := append([]string{"remote", "set-branches", "origin"}, args...)
 	return c.cmd(checkout...)
@@ -82,9 +174,10 @@ func (c *client) SetUpstreamTo(args ...string) error {
 	return c.cmd(branch...)
 }
 
+// IsPorcelain reports whether the working tree has no staged, unstaged or untracked changes
 func (c *client) IsPorcelain() bool {
-	err := c.cmd("diff", "--exit-code")
-	return err == nil
+	status, err := c.Status()
+	return err == nil && status.Clean()
 }
 
 func (c *client) PushTag(tag string, annotate ...string) error {
@@ -123,23 +216,32 @@ func (c *client) CherryPick(commitOrContinue string) error {
 	return nil
 }
 
+// StatConflicts returns the paths with unresolved merge conflicts, see Conflicts
 func (c *client) StatConflicts() ([]string, error) {
-	cmd := exec.Command("git", "diff", "--name-only", "--relative", "--diff-filter=U")
-	cmd.Dir = c.dir
+	return c.Conflicts()
+}
 
-	out, err := cmd.Output()
-	if err != nil {
-		return []string{}, err
-	}
+func (c *client) AbortCherryPick() error {
+	return c.cmd("cherry-pick", "--abort")
+}
 
-	files := strings.Split(string(out), "\n")
+// ResetHard resets the branch and working tree to ref, discarding any changes
+func (c *client) ResetHard(ref string) error {
+	return c.cmd("reset", "--hard", ref)
+}
 
-	conflicts := []string{}
-	for _, file := range files {
-		if file != "" {
-			conflicts = append(conflicts, file)
-		}
+// Stash stashes the changes, including untracked files.
+// Returns false if there was nothing to stash, in which case StashPop must not be called.
+func (c *client) Stash(message string) (bool, error) {
+	if c.IsPorcelain() {
+		return false, nil
 	}
+	if err := c.cmd("stash", "push", "--include-untracked", "-m", message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	return conflicts, nil
+func (c *client) StashPop() error {
+	return c.cmd("stash", "pop")
 }
@@ -0,0 +1,89 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPushWith(t *testing.T) {
+	onBranch := func(branch string) *Fake {
+		return NewFake().On("git status", "# branch.head "+branch+"\x00", 0)
+	}
+
+	t.Run("It pushes HEAD to the current branch", func(t *testing.T) {
+		fake := onBranch("release/1.0.0")
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		assertNoError(t, git.Push())
+		assertEqual(t, fake.Commands()[1], "git push origin HEAD:refs/heads/release/1.0.0")
+	})
+
+	t.Run("It sets the upstream and leases against the expected sha", func(t *testing.T) {
+		fake := NewFake()
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		err := git.PushWith(PushOptions{Remote: "fork", Branch: "release/1.0.0", SetUpstream: true, ForceWithLease: true, ExpectedSha: "abc123"})
+		assertNoError(t, err)
+
+		want := []string{"git push --set-upstream --force-with-lease=release/1.0.0:abc123 fork HEAD:refs/heads/release/1.0.0"}
+		if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("It refuses to push to a protected branch", func(t *testing.T) {
+		fake := onBranch("trunk")
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		if err := git.Push(); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		if err := git.PushWith(PushOptions{Branch: "main"}); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		assertEqual(t, len(fake.Commands()), 1)
+	})
+
+	t.Run("It requires a branch", func(t *testing.T) {
+		fake := NewFake()
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		if err := git.PushWith(PushOptions{Remote: "fork"}); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		if err := git.Push(); err == nil {
+			t.Fatal("Expected an error when HEAD is not on a branch, got nil")
+		}
+		assertEqual(t, len(fake.Commands()), 1)
+	})
+}
+
+func TestRemoteSha(t *testing.T) {
+
+	t.Run("It returns the sha of the remote branch", func(t *testing.T) {
+		fake := NewFake().On("git ls-remote", "abc123\trefs/heads/release/1.0.0\n", 0)
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		sha, err := git.RemoteSha("origin", "release/1.0.0")
+		assertNoError(t, err)
+		assertEqual(t, sha, "abc123")
+	})
+
+	t.Run("It returns an empty sha when the branch does not exist", func(t *testing.T) {
+		fake := NewFake().On("git ls-remote", "", 2)
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		exists, err := git.RemoteExists("origin", "release/1.0.0")
+		assertNoError(t, err)
+		assertEqual(t, exists, false)
+	})
+
+	t.Run("It returns an error when the remote can't be reached", func(t *testing.T) {
+		fake := NewFake().On("git ls-remote", "", 128)
+		git := NewGitCmd(CmdProps{Dir: "/repo", Backend: fake})
+
+		if _, err := git.RemoteExists("origin", "release/1.0.0"); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+}
@@ -0,0 +1,332 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitQueries are read-only git queries returning typed results.
+// They never stream to the console, use the GitCmds for anything that changes the repo.
+type GitQueries interface {
+	Status() (GitStatus, error)
+	CurrentBranch() (string, error)
+	LogBetween(from, to string) ([]GitCommit, error)
+	LookupTag(tag string) (string, error)
+	Conflicts() ([]string, error)
+	DiffStats(from, to string) ([]DiffStat, error)
+	RevParse(ref string) (string, error)
+	HeadSha() (string, error)
+	ListTags(pattern string) ([]string, error)
+	IsAncestor(commit, ref string) (bool, error)
+	HasRemote(name string) (bool, error)
+	RemoteURL(name string) (string, error)
+}
+
+// GitStatus is the parsed output of `git status --porcelain=v2 --branch`
+type GitStatus struct {
+	Head     string
+	Branch   string
+	Upstream string
+	Ahead    int
+	Behind   int
+	Files    []FileStatus
+}
+
+// FileStatus is a changed file. Staged and Unstaged use the status letters of
+// `git status` (M, A, D, R, C, U) and '.' when unchanged.
+type FileStatus struct {
+	Path       string
+	OrigPath   string
+	Staged     byte
+	Unstaged   byte
+	Untracked  bool
+	Conflicted bool
+}
+
+// Clean reports whether there are no staged, unstaged or untracked changes
+func (s GitStatus) Clean() bool {
+	return len(s.Files) == 0
+}
+
+// HasTrackedChanges reports whether tracked files changed, which is what `git commit -a` picks up
+func (s GitStatus) HasTrackedChanges() bool {
+	for _, f := range s.Files {
+		if !f.Untracked {
+			return true
+		}
+	}
+	return false
+}
+
+// GitCommit is a commit listed by LogBetween
+type GitCommit struct {
+	Sha     string
+	Author  string
+	Date    time.Time
+	Subject string
+}
+
+// DiffStat is the number of lines changed in a file, see DiffStats
+type DiffStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+func (c *client) Status() (GitStatus, error) {
+	out, err := c.output("status", "--porcelain=v2", "--branch", "-z")
+	if err != nil {
+		return GitStatus{}, err
+	}
+	return ParseStatus(out)
+}
+
+func (c *client) CurrentBranch() (string, error) {
+	s, err := c.Status()
+	if err != nil {
+		return "", err
+	}
+	if s.Branch == "(detached)" {
+		return "", errors.New("HEAD is detached")
+	}
+	return s.Branch, nil
+}
+
+// LogBetween returns the commits reachable from to but not from, newest first
+func (c *client) LogBetween(from, to string) ([]GitCommit, error) {
+	out, err := c.output("log", "--format=%H%x1f%an%x1f%aI%x1f%s%x1e", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(out)
+}
+
+// LookupTag returns the sha of the commit the tag points to, or "" if the tag does not exist
+func (c *client) LookupTag(tag string) (string, error) {
+	out, err := c.output("rev-parse", "--verify", "--quiet", "refs/tags/"+tag+"^{commit}")
+	if err != nil {
+		// --quiet exits with 1 and no output when the tag is missing
+		var cmdErr *CmdError
+		if errors.As(err, &cmdErr) && cmdErr.Result.ExitCode == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Conflicts returns the paths with unresolved merge conflicts
+func (c *client) Conflicts() ([]string, error) {
+	s, err := c.Status()
+	if err != nil {
+		return []string{}, err
+	}
+	conflicts := []string{}
+	for _, f := range s.Files {
+		if f.Conflicted {
+			conflicts = append(conflicts, f.Path)
+		}
+	}
+	return conflicts, nil
+}
+
+func (c *client) DiffStats(from, to string) ([]DiffStat, error) {
+	out, err := c.output("diff", "--numstat", "-z", from, to)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(out)
+}
+
+// RevParse returns the sha of the commit ref points to
+func (c *client) RevParse(ref string) (string, error) {
+	out, err := c.output("rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (c *client) HeadSha() (string, error) {
+	return c.RevParse("HEAD")
+}
+
+// ListTags returns the tags matching the glob pattern, e.g. "rnmobile/*", sorted by version
+func (c *client) ListTags(pattern string) ([]string, error) {
+	out, err := c.output("tag", "--list", "--sort=version:refname", pattern)
+	if err != nil {
+		return nil, err
+	}
+	tags := []string{}
+	for _, t := range strings.Split(out, "\n") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
+// IsAncestor checks if commit is reachable from ref, i.e. it has already been merged into ref
+func (c *client) IsAncestor(commit, ref string) (bool, error) {
+	_, err := c.output("merge-base", "--is-ancestor", commit, ref)
+	if err == nil {
+		return true, nil
+	}
+	// --is-ancestor exits with 1 when it is not an ancestor, other codes are errors
+	var cmdErr *CmdError
+	if errors.As(err, &cmdErr) && cmdErr.Result.ExitCode == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
+func (c *client) HasRemote(name string) (bool, error) {
+	out, err := c.output("remote")
+	if err != nil {
+		return false, err
+	}
+	for _, r := range strings.Fields(out) {
+		if r == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *client) RemoteURL(name string) (string, error) {
+	out, err := c.output("remote", "get-url", name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ParseStatus parses the output of `git status --porcelain=v2 --branch -z`
+// See https://git-scm.com/docs/git-status#_porcelain_format_version_2
+func ParseStatus(out string) (GitStatus, error) {
+	s := GitStatus{}
+	entries := strings.Split(out, "\x00")
+
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if e == "" {
+			continue
+		}
+
+		switch e[0] {
+		case '#':
+			parseBranchHeader(e, &s)
+
+		case '1':
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			f := strings.SplitN(e, " ", 9)
+			if len(f) != 9 {
+				return s, fmt.Errorf("unexpected status entry %q", e)
+			}
+			s.Files = append(s.Files, FileStatus{Path: f[8], Staged: f[1][0], Unstaged: f[1][1]})
+
+		case '2':
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>, followed by the original path
+			f := strings.SplitN(e, " ", 10)
+			if len(f) != 10 || i+1 >= len(entries) {
+				return s, fmt.Errorf("unexpected status entry %q", e)
+			}
+			i++
+			s.Files = append(s.Files, FileStatus{Path: f[9], OrigPath: entries[i], Staged: f[1][0], Unstaged: f[1][1]})
+
+		case 'u':
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			f := strings.SplitN(e, " ", 11)
+			if len(f) != 11 {
+				return s, fmt.Errorf("unexpected status entry %q", e)
+			}
+			s.Files = append(s.Files, FileStatus{Path: f[10], Staged: f[1][0], Unstaged: f[1][1], Conflicted: true})
+
+		case '?':
+			s.Files = append(s.Files, FileStatus{Path: strings.TrimPrefix(e, "? "), Staged: '.', Unstaged: '.', Untracked: true})
+
+		case '!':
+			// ignored files are only listed with --ignored
+		default:
+			return s, fmt.Errorf("unexpected status entry %q", e)
+		}
+	}
+	return s, nil
+}
+
+func parseBranchHeader(e string, s *GitStatus) {
+	f := strings.Fields(e)
+	if len(f) < 3 {
+		return
+	}
+	switch f[1] {
+	case "branch.oid":
+		s.Head = f[2]
+	case "branch.head":
+		s.Branch = f[2]
+	case "branch.upstream":
+		s.Upstream = f[2]
+	case "branch.ab":
+		if len(f) == 4 {
+			s.Ahead, _ = strconv.Atoi(strings.TrimPrefix(f[2], "+"))
+			s.Behind, _ = strconv.Atoi(strings.TrimPrefix(f[3], "-"))
+		}
+	}
+}
+
+func parseLog(out string) ([]GitCommit, error) {
+	commits := []GitCommit{}
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimSpace(rec)
+		if rec == "" {
+			continue
+		}
+		f := strings.Split(rec, "\x1f")
+		if len(f) != 4 {
+			return commits, fmt.Errorf("unexpected log entry %q", rec)
+		}
+		date, err := time.Parse(time.RFC3339, f[2])
+		if err != nil {
+			return commits, err
+		}
+		commits = append(commits, GitCommit{Sha: f[0], Author: f[1], Date: date, Subject: f[3]})
+	}
+	return commits, nil
+}
+
+// Parses `git diff --numstat -z`. Renames are listed as an empty path followed by the old and new paths.
+func parseNumstat(out string) ([]DiffStat, error) {
+	stats := []DiffStat{}
+	entries := strings.Split(out, "\x00")
+
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if e == "" {
+			continue
+		}
+		f := strings.SplitN(e, "\t", 3)
+		if len(f) != 3 {
+			return stats, fmt.Errorf("unexpected numstat entry %q", e)
+		}
+
+		ds := DiffStat{Path: f[2]}
+		if f[0] == "-" && f[1] == "-" {
+			ds.Binary = true
+		} else {
+			ds.Added, _ = strconv.Atoi(f[0])
+			ds.Deleted, _ = strconv.Atoi(f[1])
+		}
+
+		if ds.Path == "" && i+2 < len(entries) {
+			// rename: skip the old path, keep the new one
+			ds.Path = entries[i+2]
+			i += 2
+		}
+		stats = append(stats, ds)
+	}
+	return stats, nil
+}
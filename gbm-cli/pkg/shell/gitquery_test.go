@@ -0,0 +1,198 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+
+	t.Run("It parses the branch headers", func(t *testing.T) {
+		out := "# branch.oid 1234abcd\x00# branch.head release/1.0.0\x00# branch.upstream origin/release/1.0.0\x00# branch.ab +2 -3\x00"
+		s, err := ParseStatus(out)
+		assertNoError(t, err)
+
+		assertEqual(t, s.Head, "1234abcd")
+		assertEqual(t, s.Branch, "release/1.0.0")
+		assertEqual(t, s.Upstream, "origin/release/1.0.0")
+		assertEqual(t, s.Ahead, 2)
+		assertEqual(t, s.Behind, 3)
+		assertEqual(t, s.Clean(), true)
+	})
+
+	t.Run("It parses changed, renamed, conflicted and untracked files", func(t *testing.T) {
+		out := "# branch.head trunk\x00" +
+			"1 .M N... 100644 100644 100644 a1 a1 path with spaces.txt\x00" +
+			"2 R. N... 100644 100644 100644 b1 b1 R100 new.txt\x00old.txt\x00" +
+			"u UU N... 100644 100644 100644 100644 c1 c2 c3 conflict.txt\x00" +
+			"? untracked.txt\x00"
+		s, err := ParseStatus(out)
+		assertNoError(t, err)
+
+		want := []FileStatus{
+			{Path: "path with spaces.txt", Staged: '.', Unstaged: 'M'},
+			{Path: "new.txt", OrigPath: "old.txt", Staged: 'R', Unstaged: '.'},
+			{Path: "conflict.txt", Staged: 'U', Unstaged: 'U', Conflicted: true},
+			{Path: "untracked.txt", Staged: '.', Unstaged: '.', Untracked: true},
+		}
+		if !reflect.DeepEqual(s.Files, want) {
+			t.Fatalf("got %+v want %+v", s.Files, want)
+		}
+		assertEqual(t, s.Clean(), false)
+		assertEqual(t, s.HasTrackedChanges(), true)
+	})
+
+	t.Run("It does not count untracked files as tracked changes", func(t *testing.T) {
+		s, err := ParseStatus("# branch.head trunk\x00? untracked.txt\x00")
+		assertNoError(t, err)
+		assertEqual(t, s.Clean(), false)
+		assertEqual(t, s.HasTrackedChanges(), false)
+	})
+
+	t.Run("It errors on unexpected entries", func(t *testing.T) {
+		if _, err := ParseStatus("1 .M\x00"); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+}
+
+func TestParseNumstat(t *testing.T) {
+
+	t.Run("It parses changed, binary and renamed files", func(t *testing.T) {
+		out := "3\t1\ta.txt\x00-\t-\timage.png\x000\t0\t\x00old.txt\x00new.txt\x00"
+		stats, err := parseNumstat(out)
+		assertNoError(t, err)
+
+		want := []DiffStat{
+			{Path: "a.txt", Added: 3, Deleted: 1},
+			{Path: "image.png", Binary: true},
+			{Path: "new.txt"},
+		}
+		if !reflect.DeepEqual(stats, want) {
+			t.Fatalf("got %+v want %+v", stats, want)
+		}
+	})
+}
+
+func TestGitQueries(t *testing.T) {
+	dir := t.TempDir()
+	sp := CmdProps{Dir: dir, Env: []string{
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	}}
+	git := NewGitCmd(sp)
+	run := NewExecCmd("git", sp)
+	write := func(name, content string) {
+		t.Helper()
+		assertNoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	assertNoError(t, run.Exec("init", "-q", "-b", "trunk"))
+	write("a.txt", "one\n")
+	assertNoError(t, run.Exec("add", "a.txt"))
+	assertNoError(t, run.Exec("commit", "-q", "-m", "First"))
+	assertNoError(t, run.Exec("tag", "v1.0.0"))
+
+	t.Run("It returns the current branch", func(t *testing.T) {
+		branch, err := git.CurrentBranch()
+		assertNoError(t, err)
+		assertEqual(t, branch, "trunk")
+	})
+
+	t.Run("It looks up tags", func(t *testing.T) {
+		sha, err := git.LookupTag("v1.0.0")
+		assertNoError(t, err)
+		assertEqual(t, len(sha), 40)
+
+		sha, err = git.LookupTag("v2.0.0")
+		assertNoError(t, err)
+		assertEqual(t, sha, "")
+	})
+
+	t.Run("It sees untracked files as not porcelain", func(t *testing.T) {
+		write("untracked.txt", "new\n")
+		defer os.Remove(filepath.Join(dir, "untracked.txt"))
+
+		assertEqual(t, git.IsPorcelain(), false)
+
+		// commit -a would not pick up the untracked file
+		assertNoError(t, git.CommitAll("Nothing to commit"))
+	})
+
+	t.Run("It lists the commits and diff stats between refs", func(t *testing.T) {
+		write("a.txt", "one\ntwo\n")
+		assertNoError(t, git.CommitAll("Second"))
+
+		commits, err := git.LogBetween("v1.0.0", "HEAD")
+		assertNoError(t, err)
+		assertEqual(t, len(commits), 1)
+		assertEqual(t, commits[0].Subject, "Second")
+		assertEqual(t, commits[0].Author, "Test")
+
+		stats, err := git.DiffStats("v1.0.0", "HEAD")
+		assertNoError(t, err)
+		if !reflect.DeepEqual(stats, []DiffStat{{Path: "a.txt", Added: 1}}) {
+			t.Fatalf("got %+v", stats)
+		}
+		assertEqual(t, git.IsPorcelain(), true)
+	})
+
+	t.Run("It lists the tags matching a pattern by version", func(t *testing.T) {
+		assertNoError(t, run.Exec("tag", "rnmobile/1.10.0"))
+		assertNoError(t, run.Exec("tag", "rnmobile/1.9.0"))
+
+		tags, err := git.ListTags("rnmobile/*")
+		assertNoError(t, err)
+		if !reflect.DeepEqual(tags, []string{"rnmobile/1.9.0", "rnmobile/1.10.0"}) {
+			t.Fatalf("got %v", tags)
+		}
+	})
+
+	t.Run("It checks if a commit is an ancestor", func(t *testing.T) {
+		head, err := git.HeadSha()
+		assertNoError(t, err)
+		tag, err := git.RevParse("v1.0.0")
+		assertNoError(t, err)
+
+		ok, err := git.IsAncestor(tag, head)
+		assertNoError(t, err)
+		assertEqual(t, ok, true)
+
+		ok, err = git.IsAncestor(head, tag)
+		assertNoError(t, err)
+		assertEqual(t, ok, false)
+
+		if _, err := git.IsAncestor("missing", head); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+
+	t.Run("It stashes and restores changes", func(t *testing.T) {
+		stashed, err := git.Stash("Nothing")
+		assertNoError(t, err)
+		assertEqual(t, stashed, false)
+
+		write("untracked.txt", "new\n")
+		stashed, err = git.Stash("Release script")
+		assertNoError(t, err)
+		assertEqual(t, stashed, true)
+		assertEqual(t, git.IsPorcelain(), true)
+
+		assertNoError(t, git.StashPop())
+		assertEqual(t, git.IsPorcelain(), false)
+	})
+
+	t.Run("It resets to a ref", func(t *testing.T) {
+		assertNoError(t, git.ResetHard("v1.0.0"))
+		os.Remove(filepath.Join(dir, "untracked.txt"))
+
+		head, err := git.HeadSha()
+		assertNoError(t, err)
+		tag, err := git.LookupTag("v1.0.0")
+		assertNoError(t, err)
+		assertEqual(t, head, tag)
+		assertEqual(t, git.IsPorcelain(), true)
+	})
+}
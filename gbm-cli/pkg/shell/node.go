@@ -0,0 +1,228 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NodeManager is a Node version manager, e.g. nvm or fnm
+type NodeManager string
+
+const (
+	Nvm    NodeManager = "nvm"
+	Fnm    NodeManager = "fnm"
+	Volta  NodeManager = "volta"
+	Asdf   NodeManager = "asdf"
+	Mise   NodeManager = "mise"
+	System NodeManager = "system"
+)
+
+// NodeRequirement is the Node version a repo asks for and where it came from
+type NodeRequirement struct {
+	Version string
+	Source  string
+}
+
+// Set the environment variable to one of the managers to skip detection
+const NodeManagerEnv = "GBM_NODE_MANAGER"
+
+// DetectNodeManager returns the first node manager found, falling back to the system node
+func DetectNodeManager() NodeManager {
+	if m := os.Getenv(NodeManagerEnv); m != "" {
+		return NodeManager(m)
+	}
+	if os.Getenv("NVM_DIR") != "" {
+		return Nvm
+	}
+	for _, m := range []NodeManager{Fnm, Volta, Mise, Asdf} {
+		if _, err := exec.LookPath(string(m)); err == nil {
+			return m
+		}
+	}
+	return System
+}
+
+// FindNodeRequirement looks for .nvmrc, .node-version or the package.json engines
+// from dir up to the root of the repo. Returns an empty requirement if there are none.
+func FindNodeRequirement(dir string) NodeRequirement {
+	dir, _ = filepath.Abs(dir)
+	for {
+		for _, f := range []string{".nvmrc", ".node-version"} {
+			path := filepath.Join(dir, f)
+			if data, err := os.ReadFile(path); err == nil {
+				if v := strings.TrimSpace(string(data)); v != "" {
+					return NodeRequirement{Version: v, Source: path}
+				}
+			}
+		}
+
+		path := filepath.Join(dir, "package.json")
+		if data, err := os.ReadFile(path); err == nil {
+			pkg := struct {
+				Engines struct{ Node string }
+			}{}
+			if json.Unmarshal(data, &pkg) == nil && pkg.Engines.Node != "" {
+				return NodeRequirement{Version: pkg.Engines.Node, Source: path}
+			}
+		}
+
+		// Stop at the root of the repo
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return NodeRequirement{}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return NodeRequirement{}
+		}
+		dir = parent
+	}
+}
+
+// exact returns the version to ask the manager for, or "" if it is a range only verification can check
+func (r NodeRequirement) exact() string {
+	v := strings.TrimPrefix(r.Version, "v")
+	if regexp.MustCompile(`^\d+(\.\d+){0,2}$`).MatchString(v) {
+		return v
+	}
+	return ""
+}
+
+// nodeCommand sets up npm to run with the manager and the required version
+func nodeCommand(m NodeManager, req NodeRequirement, cmds ...string) *exec.Cmd {
+	version := req.exact()
+	switch m {
+	case Nvm:
+		use := "nvm use"
+		if version != "" {
+			use += " " + version
+		}
+		script := fmt.Sprintf(`. "$NVM_DIR/nvm.sh" && %s >/dev/null && npm %s`, use, shellQuote(cmds))
+		return exec.Command("bash", "-l", "-c", script)
+	case Fnm:
+		args := []string{"exec"}
+		if version != "" {
+			args = append(args, "--using="+version)
+		}
+		args = append(append(args, "--", "npm"), cmds...)
+		return exec.Command("fnm", args...)
+	case Volta:
+		args := []string{"run"}
+		if version != "" {
+			args = append(args, "--node", version)
+		}
+		args = append(append(args, "npm"), cmds...)
+		return exec.Command("volta", args...)
+	case Mise:
+		tool := "node"
+		if version != "" {
+			tool += "@" + version
+		}
+		args := append([]string{"exec", tool, "--", "npm"}, cmds...)
+		return exec.Command("mise", args...)
+	case Asdf:
+		cmd := exec.Command("asdf", append([]string{"exec", "npm"}, cmds...)...)
+		if version != "" {
+			cmd.Env = append(os.Environ(), "ASDF_NODEJS_VERSION="+version)
+		}
+		return cmd
+	default:
+		return exec.Command("npm", cmds...)
+	}
+}
+
+// NodeSatisfies checks if the node version satisfies the requirement.
+// Handles exact and partial versions (20, 20.10), lts aliases and the common
+// range operators (>=, >, <=, <, ^, ~, x, ||) used in package.json engines.
+func NodeSatisfies(active, required string) bool {
+	required = strings.TrimSpace(required)
+	if required == "" || required == "*" || strings.HasPrefix(required, "lts") || required == "node" {
+		return true
+	}
+	for _, alt := range strings.Split(required, "||") {
+		ok := true
+		for _, c := range strings.Fields(alt) {
+			if !satisfiesComparator(active, c) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+var comparatorRe = regexp.MustCompile(`^(>=|<=|>|<|\^|~|=)?v?(\d+|x|\*)(?:\.(\d+|x|\*))?(?:\.(\d+|x|\*))?$`)
+
+func satisfiesComparator(active, c string) bool {
+	m := comparatorRe.FindStringSubmatch(c)
+	if m == nil {
+		return false
+	}
+	a := parseNodeVersion(active)
+	op := m[1]
+
+	// the number of parts given, wildcards end the version
+	want := []int{}
+	for _, p := range m[2:] {
+		if p == "" || p == "x" || p == "*" {
+			break
+		}
+		n, _ := strconv.Atoi(p)
+		want = append(want, n)
+	}
+	cmp := compareParts(a, want)
+
+	switch op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	case "^":
+		return cmp >= 0 && len(want) > 0 && a[0] == want[0]
+	case "~":
+		return cmp >= 0 && compareParts(a, want[:min(len(want), 2)]) == 0
+	default:
+		return cmp == 0
+	}
+}
+
+// compares only the parts given in want, so 20.10.1 equals 20
+func compareParts(a [3]int, want []int) int {
+	for i, w := range want {
+		if a[i] < w {
+			return -1
+		}
+		if a[i] > w {
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseNodeVersion(v string) [3]int {
+	parts := [3]int{}
+	for i, p := range strings.SplitN(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".", 3) {
+		parts[i], _ = strconv.Atoi(p)
+	}
+	return parts
+}
+
+func shellQuote(args []string) string {
+	quoted := []string{}
+	for _, a := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(a, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,66 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNodeSatisfies(t *testing.T) {
+	tests := []struct {
+		active, required string
+		want             bool
+	}{
+		{"v20.10.0", "20.10.0", true},
+		{"v20.10.0", "v20", true},
+		{"v20.10.0", "20.9", false},
+		{"v18.17.1", ">=18.0.0", true},
+		{"v16.20.0", ">=18.0.0", false},
+		{"v20.10.0", "^20.5.0", true},
+		{"v21.0.0", "^20.5.0", false},
+		{"v20.10.3", "~20.10.0", true},
+		{"v20.11.0", "~20.10.0", false},
+		{"v18.0.0", "^16 || ^18", true},
+		{"v19.0.0", ">=18 <19", false},
+		{"v20.10.0", "20.x", true},
+		{"v20.10.0", "lts/iron", true},
+	}
+
+	for _, tt := range tests {
+		t.Run("It checks "+tt.active+" against "+tt.required, func(t *testing.T) {
+			assertEqual(t, NodeSatisfies(tt.active, tt.required), tt.want)
+		})
+	}
+}
+
+func TestFindNodeRequirement(t *testing.T) {
+
+	t.Run("It reads the .nvmrc at the root of the repo", func(t *testing.T) {
+		root := t.TempDir()
+		pkg := filepath.Join(root, "packages", "editor")
+		assertNoError(t, os.MkdirAll(filepath.Join(root, ".git"), os.ModePerm))
+		assertNoError(t, os.MkdirAll(pkg, os.ModePerm))
+		assertNoError(t, os.WriteFile(filepath.Join(root, ".nvmrc"), []byte("v20.10.0\n"), 0644))
+
+		req := FindNodeRequirement(pkg)
+		assertEqual(t, req.Version, "v20.10.0")
+		assertEqual(t, req.Source, filepath.Join(root, ".nvmrc"))
+	})
+
+	t.Run("It falls back to the package.json engines", func(t *testing.T) {
+		root := t.TempDir()
+		assertNoError(t, os.MkdirAll(filepath.Join(root, ".git"), os.ModePerm))
+		assertNoError(t, os.WriteFile(filepath.Join(root, "package.json"), []byte(`{"engines": {"node": ">=20.10.0"}}`), 0644))
+
+		assertEqual(t, FindNodeRequirement(root).Version, ">=20.10.0")
+	})
+
+	t.Run("It does not look outside of the repo", func(t *testing.T) {
+		root := t.TempDir()
+		repo := filepath.Join(root, "repo")
+		assertNoError(t, os.MkdirAll(filepath.Join(repo, ".git"), os.ModePerm))
+		assertNoError(t, os.WriteFile(filepath.Join(root, ".nvmrc"), []byte("18"), 0644))
+
+		assertEqual(t, FindNodeRequirement(repo).Version, "")
+	})
+}
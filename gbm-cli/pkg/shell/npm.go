@@ -1,42 +1,57 @@
 package shell
 
 import (
-	"os"
-	"os/exec"
-	"strings"
+	"encoding/json"
+	"fmt"
 )
 
 type NpmCmds interface {
+	Results
 	Install(...string) error
 	Ci() error
 	Run(...string) error
 	RunIn(string, ...string) error
 	Version(string) error
 	VersionIn(string, string) error
+	NodeVersion() (string, error)
+	VerifyNode() error
 }
 
-// Check to see if a node manager is available and set up the command accordingly
-func switchNodeCmd(cmds ...string) *exec.Cmd {
-
-	// Check if nvm is installed
-	if os.Getenv("NVM_DIR") != "" {
-		nvmCmd := "nvm use && npm " + strings.Join(cmds, " ")
-		nvmCheck := exec.Command("bash", "-l", "-c", "nvm")
-		if err := nvmCheck.Run(); err != nil {
-			// Load nvm before running npm
-			return exec.Command("bash", "-l", "-c", ". $NVM_DIR/nvm.sh && "+nvmCmd)
-		} else {
-			return exec.Command("bash", "-l", "-c", nvmCmd)
-		}
+// Reads the node version npm runs with from `npm version --json`
+func (c *client) NodeVersion() (string, error) {
+	out, err := c.output("version", "--json")
+	if err != nil {
+		return "", err
 	}
+	versions := map[string]string{}
+	if err := json.Unmarshal([]byte(out), &versions); err != nil {
+		return "", fmt.Errorf("unable to read the node version: %v", err)
+	}
+	return versions["node"], nil
+}
 
-	// Other node managers can be added here...
-
-	// Use system node
-	return exec.Command("npm", cmds...)
+// VerifyNode checks the active node version satisfies the version the repo asks for
+func (c *client) VerifyNode() error {
+	req := FindNodeRequirement(c.dir)
+	if req.Version == "" {
+		return nil
+	}
+	active, err := c.NodeVersion()
+	if err != nil {
+		return err
+	}
+	if !NodeSatisfies(active, req.Version) {
+		return fmt.Errorf("node %s is required by %s but %s resolved node %s. "+
+			"Install the required version with your node manager (nvm, fnm, volta, asdf or mise) or set %s to the manager to use",
+			req.Version, req.Source, DetectNodeManager(), active, NodeManagerEnv)
+	}
+	return nil
 }
 
 func (c *client) Ci() error {
+	if err := c.VerifyNode(); err != nil {
+		return err
+	}
 	return c.cmd("ci")
 }
 
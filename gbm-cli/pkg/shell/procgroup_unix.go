@@ -0,0 +1,40 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// hasTty reports whether the CLI has a controlling terminal, which git, ssh and gpg open to prompt for credentials
+var hasTty = sync.OnceValue(func() bool {
+	f, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+})
+
+// Run the command in its own process group so children (e.g. npm scripts) are killed with it.
+// With a terminal the command stays in the foreground group instead, a background group
+// would be stopped when a prompt reads the terminal. Ctrl-C reaches its children there.
+func setProcessGroup(cmd *exec.Cmd) {
+	if hasTty() {
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+		return cmd.Process.Kill()
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
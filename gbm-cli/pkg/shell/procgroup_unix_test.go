@@ -0,0 +1,35 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func init() {
+	// Run the commands of the tests in their own group, whether they run in a terminal or not
+	hasTty = func() bool { return false }
+}
+
+func TestProcessGroup(t *testing.T) {
+
+	t.Run("It keeps the command in the foreground group with a terminal", func(t *testing.T) {
+		defer func(f func() bool) { hasTty = f }(hasTty)
+		hasTty = func() bool { return true }
+
+		cmd := exec.Command("true")
+		setProcessGroup(cmd)
+		if cmd.SysProcAttr != nil {
+			t.Fatalf("Expected the command not to get its own group, got %+v", cmd.SysProcAttr)
+		}
+	})
+
+	t.Run("It runs the command in its own group without a terminal", func(t *testing.T) {
+		cmd := exec.Command("true")
+		setProcessGroup(cmd)
+		if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+			t.Fatalf("Expected the command to get its own group")
+		}
+	})
+}
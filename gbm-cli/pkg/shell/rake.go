@@ -1,6 +1,7 @@
 package shell
 
 type RakeCmds interface {
+	Results
 	Dependencies() error
 }
 
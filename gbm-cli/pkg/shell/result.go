@@ -0,0 +1,144 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wordpress-mobile/release-toolkit-gutenberg-mobile/gbm-cli/pkg/console"
+)
+
+// StderrTailLines is the number of stderr lines kept in a CmdError
+var StderrTailLines = 20
+
+// Result is the outcome of running a command
+type Result struct {
+	Bin      string
+	Args     []string
+	Dir      string
+	ExitCode int
+	Duration time.Duration
+	Stdout   string
+	Stderr   string
+}
+
+// String returns the command line without the credentials of urls, e.g. `git push origin HEAD`
+func (r Result) String() string {
+	return strings.Join(append([]string{filepath.Base(r.Bin)}, redactAll(r.Args)...), " ")
+}
+
+// StderrTail returns the last n lines of stderr, without the credentials of urls
+func (r Result) StderrTail(n int) string {
+	return Redact(tail(r.Stderr, n))
+}
+
+func tail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CmdError is returned when a command fails to start or exits with a non zero code
+type CmdError struct {
+	Result Result
+	Err    error
+}
+
+func (e *CmdError) Error() string {
+	msg := fmt.Sprintf("`%s` failed (exit code %d after %s): %v", e.Result, e.Result.ExitCode, e.Result.Duration.Round(time.Millisecond), e.Err)
+	if tail := e.Result.StderrTail(StderrTailLines); tail != "" {
+		msg += "\n" + tail
+	}
+	if path := LogPath(); path != "" {
+		msg += "\nSee the full output in " + path
+	}
+	return msg
+}
+
+func (e *CmdError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	logMu   sync.Mutex
+	logFile *os.File
+	history []Result
+)
+
+// HistoryLimit is the number of results kept by History
+var HistoryLimit = 100
+
+// OpenLog creates the log file for this run at path. All command output is tee'd to it.
+func OpenLog(path string) error {
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	if logFile != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	// Only the user can read the output of the commands
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	logFile = f
+	return nil
+}
+
+// LogPath returns the path of the log file, if one is open
+func LogPath() string {
+	logMu.Lock()
+	defer logMu.Unlock()
+	if logFile == nil {
+		return ""
+	}
+	return logFile.Name()
+}
+
+// History returns the results of the last HistoryLimit commands, with the tail of their output.
+// The full output is in the log file.
+func History() []Result {
+	logMu.Lock()
+	defer logMu.Unlock()
+	return append([]Result{}, history...)
+}
+
+// logWriter serializes writes from stdout and stderr to the log file
+type logWriter struct{}
+
+func (logWriter) Write(p []byte) (int, error) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	if logFile == nil {
+		return len(p), nil
+	}
+	return logFile.Write(p)
+}
+
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(logWriter{}, format, args...)
+}
+
+func record(r Result) {
+	console.Record("shell command", "cmd", r.String(), "dir", r.Dir, "exit", r.ExitCode, "duration", r.Duration.Round(time.Millisecond).String())
+	logf("=== exit %d in %s\n\n", r.ExitCode, r.Duration.Round(time.Millisecond))
+
+	// Installs output a lot, only keep what CmdError shows
+	r.Args = redactAll(r.Args)
+	r.Stdout = tail(r.Stdout, StderrTailLines)
+	r.Stderr = tail(r.Stderr, StderrTailLines)
+	logMu.Lock()
+	history = append(history, r)
+	if len(history) > HistoryLimit {
+		history = append([]Result{}, history[len(history)-HistoryLimit:]...)
+	}
+	logMu.Unlock()
+}
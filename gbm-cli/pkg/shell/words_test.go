@@ -0,0 +1,40 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+
+	t.Run("It splits on whitespace", func(t *testing.T) {
+		got, err := SplitWords("  docker run\t--rm  image ")
+		assertNoError(t, err)
+		assertWords(t, got, "docker", "run", "--rm", "image")
+	})
+
+	t.Run("It keeps quoted arguments together", func(t *testing.T) {
+		got, err := SplitWords(`sh -c 'bundle install && rake dependencies' -v "{dir}:/src dir" ""`)
+		assertNoError(t, err)
+		assertWords(t, got, "sh", "-c", "bundle install && rake dependencies", "-v", "{dir}:/src dir", "")
+	})
+
+	t.Run("It unescapes backslashes", func(t *testing.T) {
+		got, err := SplitWords(`echo a\ b "c\"d" 'e\f'`)
+		assertNoError(t, err)
+		assertWords(t, got, "echo", "a b", `c"d`, `e\f`)
+	})
+
+	t.Run("It returns an error for an unterminated quote", func(t *testing.T) {
+		if _, err := SplitWords(`docker run 'image`); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func assertWords(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}